@@ -2,40 +2,163 @@ package main
 
 import (
 	"bufio"
+	"bytes"
 	"context"
+	"encoding/csv"
 	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"math"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
+	"regexp"
+	"runtime/debug"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode"
 
 	"golang.org/x/oauth2/google"
 	"google.golang.org/api/sheets/v4"
-
-	"github.com/dgraph-io/badger"
 )
 
 type DeckSlot struct {
-	amount   int
-	cardName string
-	card     *ScryfallCard
+	amount     int
+	deckAmount int // how many of the copies are in the main deck (the rest are in the sideboard)
+	cardName   string
+	card       *ScryfallCard
 }
 
 type PlayerPool struct {
 	player  string
 	record  string
+	wins    int
+	losses  int
 	uri     string
 	isAlive bool
 	team    string
 	cards   []DeckSlot
-	facts   map[string]int
+	stats   PoolStats
+
+	previousUri  string // the pool before the latest add-pack, when the sheet has a history of pool links for the player
+	missingCards int    // cards we couldn't get data for (only happens when using cached data)
+
+	// How the strength was put together: each deck's summed strength and the cards that counted toward it
+	deckStrengths map[string]float64
+	deckTopCards  map[string][]CardStrength
+}
+
+// Everything we've worked out about a pool.  Counts are cards in the pool unless noted otherwise.
+type PoolStats struct {
+	Bombs                int     `json:"bombs"`
+	BombColors           int     `json:"bombColors"` // how many colours the bombs are spread across
+	Duds                 int     `json:"duds"`
+	TopCommons           int     `json:"topCommons"`
+	White                int     `json:"white"`
+	Blue                 int     `json:"blue"`
+	Black                int     `json:"black"`
+	Red                  int     `json:"red"`
+	Green                int     `json:"green"`
+	Gold                 int     `json:"gold"`
+	Colourless           int     `json:"colourless"`
+	ColorlessNonArtifact int     `json:"colorlessNonArtifact"`
+	PlayableAnywhere     int     `json:"playableAnywhere"`
+	LikelyPair           string  `json:"likelyPair"` // the colour pair with the most playables
+	LikelyPairCount      int     `json:"likelyPairCount"`
+	Cmc                  float64 `json:"cmc"`   // total, not average
+	Curve                [8]int  `json:"curve"` // non-land cards at mana value 0, 1, ... 6, and then 7+
+	Creatures            int     `json:"creatures"`
+	Instants             int     `json:"instants"`
+	Sorceries            int     `json:"sorceries"`
+	Artifacts            int     `json:"artifacts"`
+	Enchantments         int     `json:"enchantments"`
+	Planeswalkers        int     `json:"planeswalkers"`
+	NonBasicLand         int     `json:"nonBasicLand"`
+	Commanders           int     `json:"commanders"`
+	TopCommanders        int     `json:"topCommanders"`
+	Playsets             int     `json:"playsets"`
+	UniqueCards          int     `json:"uniqueCards"`
+	CostUSD              float64 `json:"costUSD"`
+	PricelessCards       int     `json:"pricelessCards"` // cards without a usd, usd_foil or eur price, which count as $0
+	TopCardValuePct      float64 `json:"topCardValuePct"`
+	Top3ValuePct         float64 `json:"top3ValuePct"`
+	Removal              int     `json:"removal"` // all removal, colourless included
+	WhiteRemoval         int     `json:"whiteRemoval"`
+	BlueRemoval          int     `json:"blueRemoval"`
+	BlackRemoval         int     `json:"blackRemoval"`
+	RedRemoval           int     `json:"redRemoval"`
+	GreenRemoval         int     `json:"greenRemoval"`
+	Evasion              int     `json:"evasion"`
+	CombatTricks         int     `json:"combatTricks"`
+	ManaAccelerants      int     `json:"manaAccelerants"`
+	EfficientPlayables   int     `json:"efficientPlayables"`
+	PlayablePct          float64 `json:"playablePct"` // share of the non-basic cards over the -playable-wr GIH WR
+	RedundantGroups      int     `json:"redundantGroups"`
+	AvgWinRate           float64 `json:"avgWinRate"`
+	AvgPick              float64 `json:"avgPick"`
+	FirstPickQuality     float64 `json:"firstPickQuality"`
+	BestCommon           string  `json:"bestCommon"`
+	BestCommonWR         float64 `json:"bestCommonWR"`
+	RawStrength          float64 `json:"rawStrength"`
+	Strength             float64 `json:"strength"`      // zero for dead pools
+	CurveStrength        float64 `json:"curveStrength"` // strength with clunky curves marked down (zero unless -curve-penalty is set, and for dead pools)
+	StrengthPercentile   int     `json:"strengthPercentile"`
+	RecordPercentile     int     `json:"recordPercentile"`
+	LuckIndex            int     `json:"luckIndex"`
+	QualityScore         int     `json:"qualityScore"` // 0 (and blank in the csv) for pools without any pick data
+	IllegalCards         int     `json:"illegalCards"`
+	IllegalCardNames     string  `json:"illegalCardNames"`
+}
+
+// A non-200 response from a site
+type WebError struct {
+	statusCode int
+	uri        string
+}
+
+func (err *WebError) Error() string {
+	return fmt.Sprintf("An error with code %d was throw trying to get a response from: %s", err.statusCode, err.uri)
+}
+
+// Outage-looking failures across the whole run
+type WebFailureTally struct {
+	mu          sync.Mutex
+	consecutive int
+	retries     int
+	down        bool  // once the failures look like an outage, every request gives up straight away
+	lastErr     error // the failure that tipped it over
+}
+
+// A reason to prefer one printing of a card over another
+type PrintingPreference struct {
+	name    string
+	prefers func(run *Run, card *ScryfallCard) bool
+}
+
+// A column in the fun facts csv
+type FunFactColumn struct {
+	name  string
+	value func(p *PlayerPool) string
+}
+
+// How early a card tends to get drafted
+type CardPick struct {
+	avgPick   float64
+	pickCount int
+}
+
+// A 17lands format, and how much its win rates count toward a blend
+type FormatWeight struct {
+	format string
+	weight float64
 }
 
 type CardStrength struct {
@@ -43,43 +166,147 @@ type CardStrength struct {
 	strength float64
 }
 
+// How long one phase of the run took
+type PhaseTiming struct {
+	phase   string
+	elapsed time.Duration
+}
+
+// How a card's pick order lines up with how well it wins
+type PickOutlier struct {
+	cardName          string
+	rarity            string
+	avgPick           float64
+	winRate           float64
+	pickPercentile    int // 100 = taken earliest
+	winRatePercentile int // 100 = wins the most
+}
+
+// One run's look at a watched card: how many pools have it, and how they're doing
+type WatchCardSnapshot struct {
+	Date       string  `json:"date"`
+	AlivePools int     `json:"alivePools"`
+	DeadPools  int     `json:"deadPools"`
+	Wins       int     `json:"wins"` // across all of the pools with the card
+	Losses     int     `json:"losses"`
+	WinPct     float64 `json:"winPct"`
+}
+
+// A team's pools, added up
+type TeamSummary struct {
+	team           string
+	players        int
+	livingPlayers  int
+	livingStrength float64
+	livingBombs    int
+	wins           int
+	losses         int
+}
+
+func (t *TeamSummary) winPct() float64 {
+	if t.wins+t.losses == 0 {
+		return 0
+	}
+	return float64(t.wins) / float64(t.wins+t.losses)
+}
+
+// How many (and what share) of the living and dead pools have a card
+type PrevalenceGap struct {
+	cardName   string
+	alivePools int
+	alivePct   float64
+	deadPools  int
+	deadPct    float64
+}
+
 // Constants that shouldn't change
-const googleApiSecretFile = "D:\\Code\\PoolParser\\asl-pools-859d88f87aef.json"
 const sealedDeckApiUriTemplate string = "https://sealeddeck.tech/api/pools/%s"
 const sealedDeckPauseMs = 100                                                       // be a good citizen
 const scryfallCardTemplate string = "https://api.scryfall.com/cards/named?exact=%s" // lookup for an exact card = sub in +'s for spaces
 const scryfallSetClauseTemplate string = "&set=%s"                                  // append on to scryfallCardTemplate when needed
 const scryfallPauseMs = 75                                                          // be a good citizen
+const scryfallSearchTemplate string = "https://api.scryfall.com/cards/search?q=%s&unique=prints&order=released"
+const scryfallBulkDataUri string = "https://api.scryfall.com/bulk-data/default-cards" // every printing, refreshed daily by scryfall
+const scryfallPricesFetchedDbKey = "scryfall_prices_fetched"
+const scryfallCollectionUri string = "https://api.scryfall.com/cards/collection" // POST up to scryfallCollectionMax identifiers at once
+const scryfallCollectionMax = 75
+const scryfallBulkPricesThreshold = 3000 // with more cached printings than this, the bulk download beats asking for them scryfallCollectionMax at a time
 const seventeenLandsTemplate string = "https://www.17lands.com/card_ratings/data?expansion=%s&format=%s&start_date=2019-01-01&end_date=%s&colors=%s"
 const seventeenLandsPauseMs = 1000
 const seventeenLandsDrawnThreshold = 100 // 1000 is a typical base.  Will be modified for rarity
+
+// Perf data for this format is cached without the format in the key
+const defaultPerformanceFormat = "PremierDraft"
 const webRetires int = 3
+const webRetryBudget = 100           // outage-looking retries allowed over the whole run
+const webMaxConsecutiveFailures = 15 // outage-looking failures in a row (across all sites) before we give up
+const maxConcurrentRequestsDefault = 4
 
-const dbPath = "D:\\Code\\PoolParser\\db"
-const outputPath = "D:\\Code\\PoolParser\\out"
-const perfOutputPath = "D:\\Code\\PoolParser\\out-perf"
 const debugging17Lands = false
 
 // League-specific constants
-const leagueSheetID string = "1cNoZe15TjOgmtTsbH1R3nX_YU9Q9E224bjVUEV0haDk"
-const poolLinkRange string = "Pools!A7:H67"
 const sheetPlayerColumnIndex = 0
 const sheetWinColumnIndex = 2
 const sheetLossColumnIndex = 3
 const sheetLinkColumnIndex = 4
 const leagueEliminationLosses = 11
+const unaffiliatedTeamName = "Unaffiliated" // team for players with a blank (or missing) team cell, when there is a team column
+const fileTeamColumnIndex = 4               // the optional Team column in a -pools-file csv
 const isSingletonLeague = true
 const deckStrengthCardsToConsider = 60
+const draftDeckStrengthCardsToConsider = 23 // a draft pool is only ~45 cards, so just grade the spells that make the deck
+const draftPoolMaxCards = 60                // with -pool-type auto, pools smaller than this are taken to be draft pools
+
+// For the curve-adjusted strength: cards at this mana value or more are high drops, and an archetype can carry this share of them before they get marked down
+const curveHighDropCmc = 5
+const curveHighDropShare = 0.2       // share of an archetype's top cards that can be high drops
+const efficientPlayableMaxCmc = 3    // the most mana an efficient playable can cost
+const curatedListMinInSetShare = 0.5 // warn when less of a curated list than this is from the sets in the pools
+const prevalenceGapMinPools = 3      // cards in fewer pools than this are too noisy for the prevalence gap report
+const expectedPlayerTolerance = 2    // how far off the expected player count we can be before complaining
+
+// The date on a daily 17lands cache key, zero-padded or not (keys from before the padding fix are still around), e.g. 17lands_MKM_WU_2024_02_05
+var perfDbKeyDatePattern = regexp.MustCompile(`_(\d{4})_(\d{1,2})_(\d{1,2})(_fetched)?$`)
+
+// Runs of characters that aren't safe in a file name on every OS
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// How much each of the strongest decks in a pool counts toward its strength (best first)
+var deckStrengthWeights = []float64{1.0, 0.8, 0.4}
+
+// Oracle text patterns (matched against lower-cased text) that flag a card as removal.  Tune these per set.
+var removalPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`destroy target (creature|planeswalker|nonland permanent|permanent)`),
+	regexp.MustCompile(`exile target (creature|planeswalker|nonland permanent|permanent)`),
+	regexp.MustCompile(`deals? (\d+|x) damage to (any target|target creature|target planeswalker)`),
+	regexp.MustCompile(`gets -(\d+|x)/-(\d+|x)`),
+	regexp.MustCompile(`fights (target|another target|up to one target)`),
+}
+
+// Oracle text patterns (matched against lower-cased text) that make an instant a combat trick.  Tune these per set.
+var combatTrickPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`gets? \+(\d+|x)/\+(\d+|x)`),
+	regexp.MustCompile(`gains? protection from`),
+	regexp.MustCompile(`gains? (hexproof|indestructible)`),
+	regexp.MustCompile(`\+1/\+1 counters? on target creature`),
+}
+
+// Oracle text patterns (matched against lower-cased text) for non-land mana sources: rocks, dorks, treasure makers.  Tune these per set.
+var manaAcceleratorPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`add \{[wubrgc]\}`),
+	regexp.MustCompile(`add (one|two|three|x) mana`),
+	regexp.MustCompile(`create (a|one|two|three|x) treasure`),
+}
+
+// Keywords (as scryfall lists them, on either face) and oracle text phrases that make a creature evasive.  Tune these per set.
+var evasionKeywords = []string{"flying", "menace", "trample", "shadow", "intimidate"}
+var evasionPhrases = []string{"can't be blocked"}
 
-// We want to track a stat for fun.  Here are some lists that we're using
-var bombList map[string]DeckSlot
+// We want to track a stat for fun.  Here are the pools behind the lists that we're using (the lists themselves live on the Run)
 var bombSealedDeckId = fmt.Sprintf(sealedDeckApiUriTemplate, "UWEl8i8M1R")
-var dudList map[string]DeckSlot
 var dudSealedDeckId = fmt.Sprintf(sealedDeckApiUriTemplate, "NIenIp5K6D")
-var topCommonList map[string]DeckSlot
 var topCommonDeckId = fmt.Sprintf(sealedDeckApiUriTemplate, "15xAsf8x53")
 // HBG-specific
-var topCommanderList map[string]DeckSlot
 var topCommanderDeckId = fmt.Sprintf(sealedDeckApiUriTemplate, "Qiso26itp4")
 
 
@@ -88,27 +315,253 @@ var mtg2CDecks = []string{"WU", "WB", "WR", "WG", "UB", "UR", "UG", "BR", "BG",
 var mtg3CDecks = []string{"WUB", "WUR", "WUG", "BRW", "GWB", "WRG", "UBR", "UBG", "RGU", "BRG"}
 var allSeventeenLandsSets = []string{"DOM", "M19", "RNA", "GRN", "WAR", "M20", "ELD", "THB", "IKO", "M21", "AKR", "ZNR", "KLR", "KHM", "STX", "AFR", "MID", "VOW", "NEO", "SNC", "HBG"} // keep ordered by release
 var seventeenLands3CSets = map[string]struct{}{"SNC": {}}
-var currentSet = "HBG"
+var archetypesFile = "archetypes.json" // per-set archetype definitions.  Sets that aren't in the file fall back to the lists above.
 var setPerformanceFormat = "PremierDraft"
-var leagueIsMonoSet = false // Should we bother looking up other sets?
-var setsInPools map[string]int = make(map[string]int)
+var leagueIsMonoSet = false                       // Should we bother looking up other sets?
+var setPerformanceBlend = make([]FormatWeight, 0) // optionally blend several formats' win rates, e.g. PremierDraft:0.7,TradDraft:0.3
+
+// Stamped in at build time with -ldflags "-X main.toolVersion=1.2.3".  Otherwise the vcs revision go embeds in the binary gets used.
+var toolVersion = ""
+
+// The clock used for date-keyed caching and output names.  Swap it out to pretend it's another day.
+var nowFunc = time.Now
+
+// All web requests go through here so that we're polite to every site, even when several requests are in flight
+var webScheduler = makeWebScheduler()
+
+// Run-wide tally of outage-looking failures, so that a dead site fails the run quickly instead of grinding through retries
+var webFailures = WebFailureTally{}
+
+// What web requests return once the failures look like an outage
+var errServicesDown = errors.New("External services appear to be down")
+
+// Every web request goes out through this client.  Swap in a different transport with makeHttpClient (e.g. to fake responses).
+var httpClient = makeHttpClient(makeHttpTransport(), webTimeout)
+
+// Except for big downloads (scryfall's bulk data), which can take longer than webTimeout to stream.  They still give up if the server never answers.
+var downloadHttpClient = makeHttpClient(makeHttpTransport(), 0)
+
+// Shared google sheets client (see getSheetsService)
+var sheetsService *sheets.Service
+
+// Command-line options
+var poolsFile = ""                                       // optional csv of Player,Wins,Losses,PoolLink[,Team] used when the google sheet can't be read
+var onColourStrength = false                             // only consider cards that fit an archetype's colours (plus colourless) when computing its strength
+var qualityWinRateWeight = 0.5                           // how much of the quality score comes from win rate (the rest comes from average pick)
+var curatedListsSkipBasics = false                       // drop basics (and injected cards) that snuck into the curated bomb/dud/etc. pools
+var maxConcurrentRequests = maxConcurrentRequestsDefault // across all of the sites we hit
+var webTimeout = 30 * time.Second                        // how long a web request can take before it's given up on (and retried)
+var userAgent = "AGLStats/1.0"                           // sent with every web request, so the sites can tell whose traffic it is
+var cardStoreKind = "badger"                             // where the cache lives between runs: badger, or sqlite for a single file that can be queried
+var poolConcurrency = 4                                  // pools fetched at once (the web scheduler still keeps each site to its own pace)
+var perPoolOutput = false                                // also write each pool's facts & cards to its own json file
+var currentSetOnlyStrength = false                       // only count cards printed in the current set toward strength
+var expectedPlayers = 0                                  // how many players the league should have (0 to skip the check)
+var explainPlayer = ""                                   // print out how this player's strength was computed
+var sheetStatusColumnIndex = -1                          // optional column that says whether a player is alive/dead, overriding their losses (-1 for none)
+var sheetTeamColumnIndex = -1                            // optional column with each player's team, for team leagues (-1 for none)
+var cachedOnly = false                                   // never go to scryfall for card data, only use what's in the database
+var funFactsOutput = ""                                  // where the fun facts go: a file name, "-" for stdout, or empty for a timestamped file in the output folder
+var outputFormats = []string{"csv"}                      // csv, json and/or jsonl, each written from the same run
+var progress io.Writer = os.Stdout                       // where progress messages go (stderr when a report takes stdout)
+var maindeckOnlyStrength = false                         // grade the submitted main deck rather than the whole pool
+var ratingsFile = ""                                     // optional csv of CardName,Rating (0-100 or a letter grade) to grade pools with instead of 17lands
+var injectedCardNames = []string{"Command Tower"}        // cards sealeddeck.tech adds to pools, which get ignored like basics
+var setCodeOverride = ""                                 // set to analyze instead of the config's currentSet
+var sheetIdOverride = ""                                 // league sheet to read instead of the config's leagueSheetID
+var configFile = "config.json"                           // json file of paths & league settings (the defaults are used if it's missing and wasn't asked for)
+var playableWinRate = 55.0                               // GIH WR (%) a card needs to count toward the pool's playable share
+var watchCardNames = make([]string, 0)                   // cards whose prevalence & results get tracked from run to run
+var curvePenalty = 0.0                                   // how much strength a high drop over the curve budget loses (0-1), for the curve-adjusted strength (0 to skip it)
+var offlineSheet = false                                 // read the pools from the copy of the sheet cached in the database, rather than from google
+var contestedColourWeight = 0.0                          // how hard cards in over-drafted colours get marked down when grading strength (0 to leave strength alone)
+var showTimings = false                                  // print how long each phase of the run took
+var detectCurrentSet = false                             // work out the current set from the pools instead of using the built-in one
+var detectSetIgnoredCodes = []string{"PLST", "SLD"}      // set codes that never count when detecting the current set (on top of the non-draftable set types)
+var efficientPlayableWinRate = 56.0                      // GIH WR (%) a card at 3 or less mana needs to count as an efficient playable
+var compareLatest = false                                // print what changed since the previous run's fun facts
+var derivedBombFloors = make(map[string]float64)         // GIH WR (%) each rarity needs to count as a bomb when deriving bombs from 17lands (empty to use the curated list)
+var addPackDiff = false                                  // list what each pool gained since the previous pool in its history (for add-pack leagues)
+var masterWinRates = false                               // add each card's best GIH WR to the alive/dead master card files
+var deadPoolsOutput = "include"                          // what to do with dead pools in the fun facts: include them, exclude them, or write them to a separate file
+var leaguePoolType = "sealed"                            // sealed, draft, or auto (guess from each pool's size).  Decides how many cards count toward strength.
+var legalityFormat = ""                                  // scryfall format name (e.g. standard, historic) that pools get checked against for banned/not legal cards (empty to skip)
+var factCardsCap = 0                                     // only count the pool's N strongest cards toward the colour, mana value & type facts (0 for the whole pool)
+var statsSheetName = "Stats"                             // tab (or range, like Stats!B2:AZ) in the league sheet that gets a copy of the fun facts each run (empty to skip)
+var priceRefreshInterval = 24 * time.Hour                // how stale cached prices can get before they're refreshed from scryfall (0 to never)
+var cardTTL = 7 * 24 * time.Hour                         // how long a cached card is good for before it's re-fetched from scryfall (0 to keep them forever)
+
+// What the letter grades in a ratings file are worth, on the same 0-100 scale as numeric ratings
+var letterGradeRatings = map[string]float64{
+	"A+": 100, "A": 95, "A-": 90,
+	"B+": 85, "B": 80, "B-": 75,
+	"C+": 70, "C": 65, "C-": 60,
+	"D+": 55, "D": 50, "D-": 45,
+	"F": 20,
+}
+
+// Scryfall set types that show up in draft boosters.  Printings from other set types (promos, masterpieces, commander decks) have odd sets & rarities.
+var draftableSetTypes = []string{"expansion", "core", "draft_innovation"}
+
+// Scryfall layouts where only one face is on the battlefield at a time (the other is the back of the card), so the front face is what the card is
+var doubleFacedLayouts = []string{"transform", "modal_dfc", "flip"}
+
+// When scryfall offers several printings of a card, these decide which one we keep (most important first).
+var printingPreferences = []PrintingPreference{
+	{"current set", func(run *Run, card *ScryfallCard) bool { return strings.EqualFold(card.Set, run.currentSet) }},
+	{"draftable set type", func(run *Run, card *ScryfallCard) bool { return isDraftableSetType(card.SetType) }},
+	{"high-res image", func(run *Run, card *ScryfallCard) bool { return card.HighresImage }},
+	{"non-promo", func(run *Run, card *ScryfallCard) bool { return !card.Promo }},
+}
+
+// The fun facts csv, column by column.  The per-archetype strengths get tacked on after these.
+var funFactColumns = []FunFactColumn{
+	{"Player", func(p *PlayerPool) string { return csvQuote(p.player) }},
+	{"Team", func(p *PlayerPool) string { return csvQuote(p.team) }},
+	{"IsAlive", func(p *PlayerPool) string { return strconv.FormatBool(p.isAlive) }},
+	{"Record", func(p *PlayerPool) string { return p.record }},
+	{"Bombs", func(p *PlayerPool) string { return strconv.Itoa(p.stats.Bombs) }},
+	{"Duds", func(p *PlayerPool) string { return strconv.Itoa(p.stats.Duds) }},
+	{"TopCommons", func(p *PlayerPool) string { return strconv.Itoa(p.stats.TopCommons) }},
+	{"W", func(p *PlayerPool) string { return strconv.Itoa(p.stats.White) }},
+	{"U", func(p *PlayerPool) string { return strconv.Itoa(p.stats.Blue) }},
+	{"B", func(p *PlayerPool) string { return strconv.Itoa(p.stats.Black) }},
+	{"R", func(p *PlayerPool) string { return strconv.Itoa(p.stats.Red) }},
+	{"G", func(p *PlayerPool) string { return strconv.Itoa(p.stats.Green) }},
+	{"Gold", func(p *PlayerPool) string { return strconv.Itoa(p.stats.Gold) }},
+	{"Colourless", func(p *PlayerPool) string { return strconv.Itoa(p.stats.Colourless) }},
+	{"Cmc", func(p *PlayerPool) string { return fmt.Sprintf("%.1f", p.stats.Cmc) }},
+	{"NonBasicLand", func(p *PlayerPool) string { return strconv.Itoa(p.stats.NonBasicLand) }},
+	{"Commanders", func(p *PlayerPool) string { return strconv.Itoa(p.stats.Commanders) }},
+	{"TopCommanders", func(p *PlayerPool) string { return strconv.Itoa(p.stats.TopCommanders) }},
+	{"Playsets", func(p *PlayerPool) string { return strconv.Itoa(p.stats.Playsets) }},
+	{"UniqueCards", func(p *PlayerPool) string { return strconv.Itoa(p.stats.UniqueCards) }},
+	{"CostUSD", func(p *PlayerPool) string { return fmt.Sprintf("%.2f", p.stats.CostUSD) }},
+	{"PricelessCards", func(p *PlayerPool) string { return strconv.Itoa(p.stats.PricelessCards) }},
+	{"Strength", func(p *PlayerPool) string { return fmt.Sprintf("%.1f", p.stats.Strength) }},
+	{"CurveStrength", func(p *PlayerPool) string { return fmt.Sprintf("%.1f", p.stats.CurveStrength) }},
+	{"WhiteRemoval", func(p *PlayerPool) string { return strconv.Itoa(p.stats.WhiteRemoval) }},
+	{"BlueRemoval", func(p *PlayerPool) string { return strconv.Itoa(p.stats.BlueRemoval) }},
+	{"BlackRemoval", func(p *PlayerPool) string { return strconv.Itoa(p.stats.BlackRemoval) }},
+	{"RedRemoval", func(p *PlayerPool) string { return strconv.Itoa(p.stats.RedRemoval) }},
+	{"GreenRemoval", func(p *PlayerPool) string { return strconv.Itoa(p.stats.GreenRemoval) }},
+	{"StrengthPercentile", func(p *PlayerPool) string { return strconv.Itoa(p.stats.StrengthPercentile) }},
+	{"RecordPercentile", func(p *PlayerPool) string { return strconv.Itoa(p.stats.RecordPercentile) }},
+	{"LuckIndex", func(p *PlayerPool) string { return strconv.Itoa(p.stats.LuckIndex) }},
+	{"Evasion", func(p *PlayerPool) string { return strconv.Itoa(p.stats.Evasion) }},
+	{"AvgPick", func(p *PlayerPool) string { return fmt.Sprintf("%.2f", p.stats.AvgPick) }},
+	{"QualityScore", func(p *PlayerPool) string {
+		if !p.hasPickData() {
+			return ""
+		}
+		return strconv.Itoa(p.stats.QualityScore)
+	}},
+	{"RedundantGroups", func(p *PlayerPool) string { return strconv.Itoa(p.stats.RedundantGroups) }},
+	{"CombatTricks", func(p *PlayerPool) string { return strconv.Itoa(p.stats.CombatTricks) }},
+	{"ColorlessNonArtifact", func(p *PlayerPool) string { return strconv.Itoa(p.stats.ColorlessNonArtifact) }},
+	{"FirstPickQuality", func(p *PlayerPool) string { return fmt.Sprintf("%.2f", p.stats.FirstPickQuality) }},
+	{"TopCardValuePct", func(p *PlayerPool) string { return fmt.Sprintf("%.1f", p.stats.TopCardValuePct) }},
+	{"Top3ValuePct", func(p *PlayerPool) string { return fmt.Sprintf("%.1f", p.stats.Top3ValuePct) }},
+	{"ManaAccelerants", func(p *PlayerPool) string { return strconv.Itoa(p.stats.ManaAccelerants) }},
+	{"BestCommon", func(p *PlayerPool) string { return csvQuote(p.stats.BestCommon) }},
+	{"BestCommonWR", func(p *PlayerPool) string { return fmt.Sprintf("%.3f", p.stats.BestCommonWR) }},
+	{"PlayableAnywhere", func(p *PlayerPool) string { return strconv.Itoa(p.stats.PlayableAnywhere) }},
+	{"BombColors", func(p *PlayerPool) string { return strconv.Itoa(p.stats.BombColors) }},
+	{"IllegalCards", func(p *PlayerPool) string { return strconv.Itoa(p.stats.IllegalCards) }},
+	{"IllegalCardNames", func(p *PlayerPool) string { return csvQuote(p.stats.IllegalCardNames) }},
+	{"LikelyPair", func(p *PlayerPool) string { return p.stats.LikelyPair }},
+	{"LikelyPairCount", func(p *PlayerPool) string { return strconv.Itoa(p.stats.LikelyPairCount) }},
+	{"EfficientPlayables", func(p *PlayerPool) string { return strconv.Itoa(p.stats.EfficientPlayables) }},
+	{"PlayablePct", func(p *PlayerPool) string { return fmt.Sprintf("%.1f", p.stats.PlayablePct) }},
+	{"Cmc0", func(p *PlayerPool) string { return strconv.Itoa(p.stats.Curve[0]) }},
+	{"Cmc1", func(p *PlayerPool) string { return strconv.Itoa(p.stats.Curve[1]) }},
+	{"Cmc2", func(p *PlayerPool) string { return strconv.Itoa(p.stats.Curve[2]) }},
+	{"Cmc3", func(p *PlayerPool) string { return strconv.Itoa(p.stats.Curve[3]) }},
+	{"Cmc4", func(p *PlayerPool) string { return strconv.Itoa(p.stats.Curve[4]) }},
+	{"Cmc5", func(p *PlayerPool) string { return strconv.Itoa(p.stats.Curve[5]) }},
+	{"Cmc6", func(p *PlayerPool) string { return strconv.Itoa(p.stats.Curve[6]) }},
+	{"Cmc7Plus", func(p *PlayerPool) string { return strconv.Itoa(p.stats.Curve[7]) }},
+	{"Removal", func(p *PlayerPool) string { return strconv.Itoa(p.stats.Removal) }},
+	{"Creatures", func(p *PlayerPool) string { return strconv.Itoa(p.stats.Creatures) }},
+	{"Instants", func(p *PlayerPool) string { return strconv.Itoa(p.stats.Instants) }},
+	{"Sorceries", func(p *PlayerPool) string { return strconv.Itoa(p.stats.Sorceries) }},
+	{"Artifacts", func(p *PlayerPool) string { return strconv.Itoa(p.stats.Artifacts) }},
+	{"Enchantments", func(p *PlayerPool) string { return strconv.Itoa(p.stats.Enchantments) }},
+	{"Planeswalkers", func(p *PlayerPool) string { return strconv.Itoa(p.stats.Planeswalkers) }},
+}
 
 func main() {
-	// Open the local badger database
-	db, err := badger.Open(badger.DefaultOptions(dbPath))
+	parseFlags()
+	defer exitIfServicesDown() // runs last, once the database has been closed
+
+	// Load up where everything lives, and which league this is
+	config, err := loadConfig(configFile, isFlagSet("config"))
+	checkError(err)
+	if setCodeOverride != "" {
+		config.CurrentSet = setCodeOverride
+	}
+	if sheetIdOverride != "" {
+		config.LeagueSheetID = sheetIdOverride
+	}
+	run := makeRun(config)
+	checkError(os.MkdirAll(run.config.OutputPath, 0755))
+	checkError(os.MkdirAll(run.config.PerfOutputPath, 0755))
+
+	// Open the local database (badger, or SQLite with -store sqlite)
+	db, err := openCardStore(cardStoreKind, run.config.DbPath)
 	if err != nil {
 		checkError(err)
 	}
 	defer db.Close()
 
+	// Subcommands do their own thing instead of the normal report
+	switch flag.Arg(0) {
+	case "repair":
+		run.repairDatabase(db)
+		return
+	case "warm":
+		// Just fill the card cache (the slow, network-bound part) so that a later run can go straight to the stats
+		warmPools, _ := run.populatePools(db, run.getAllPools(db))
+		fmt.Fprintf(progress, "Warmed the card cache for %d pools\n", len(warmPools))
+		return
+	case "backfill":
+		run.backfillCards(db, run.getAllPools(db), flag.Arg(1) == "suspect")
+		return
+	case "rebuild-cache":
+		rebuildCache(db, getCardStorePath(cardStoreKind, run.config.DbPath))
+		return
+	}
+
 	// Initialize with the current set
-	setsInPools[currentSet] = 1
+	run.setsInPools[run.currentSet] = 1
+	run.archetypesBySet, err = loadArchetypes(archetypesFile)
+	checkError(err)
 
-	// Grab all of the pools in the google sheet
-	var allPools = getPoolsFromSheet(leagueSheetID, poolLinkRange, googleApiSecretFile) //[0:1]
+	// Grab all of the pools
+	phaseStart := time.Now()
+	allPools := run.getAllPools(db)
+	phaseStart = run.recordPhaseTiming("getPoolsFromSheet", phaseStart)
 
 	// Fetch all the card data for the pools, and populate it into the supplied pool objects
-	populatePools(db, allPools)
+	refreshCardPrices(db)
+	phaseStart = run.recordPhaseTiming("refreshCardPrices", phaseStart)
+	allPools, _ = run.populatePools(db, allPools)
+	phaseStart = run.recordPhaseTiming("populatePools", phaseStart)
+	if webFailures.isDown() {
+		return // there's nothing worth reporting if the sites are down
+	}
+	reportPoolsWithoutCardData(allPools)
+	if detectCurrentSet {
+		if setCode := getMostCommonSet(allPools); setCode != "" {
+			fmt.Fprintln(progress, "Detected the current set from the pools: ", setCode)
+			run.currentSet = setCode
+			run.setsInPools[run.currentSet] = 1
+		}
+	}
+	if addPackDiff {
+		run.processAddPacks(allPools)
+		phaseStart = run.recordPhaseTiming("processAddPacks", phaseStart)
+	}
 
 	// Filter the living from the dead
 	alivePools := make([]PlayerPool, 0)
@@ -120,366 +573,2581 @@ func main() {
 			deadPools = append(deadPools, p)
 		}
 	}
-	fmt.Printf("\n\nFound %d living pools and %d dead pools....\n", len(alivePools), len(deadPools))
+	fmt.Fprintf(progress, "\n\nFound %d living pools and %d dead pools....\n", len(alivePools), len(deadPools))
+
+	// Load up data about how the cards perform
+	cardStrengthByDeck := run.loadCardStrengths(db)
+	phaseStart = run.recordPhaseTiming("loadCardStrengths", phaseStart)
+	if webFailures.isDown() {
+		return
+	}
 
 	// Now dump stats for the pools
-	fmt.Println("Analyzing living pools...")
-	processPools(db, alivePools, "alive")
+	fmt.Fprintln(progress, "Analyzing living pools...")
+	run.processPools(db, alivePools, "alive", cardStrengthByDeck)
+	phaseStart = run.recordPhaseTiming("processPools (alive)", phaseStart)
 
-	fmt.Println("Analyzing dead pools...")
-	processPools(db, deadPools, "dead")
+	fmt.Fprintln(progress, "Analyzing dead pools...")
+	run.processPools(db, deadPools, "dead", cardStrengthByDeck)
+	phaseStart = run.recordPhaseTiming("processPools (dead)", phaseStart)
+
+	// Which cards show up a lot more in the dead pools than the living ones?
+	run.processPrevalenceGap(alivePools, deadPools)
+	run.processWatchCards(db, allPools)
 
 	// And finally, do some "fun" analysis
-	loadFunFactLists(db)
-	processFunFacts(db, allPools)
+	run.loadFunFactLists(db, cardStrengthByDeck)
+	phaseStart = run.recordPhaseTiming("loadFunFactLists", phaseStart)
+	run.processFunFacts(db, allPools, cardStrengthByDeck)
+	phaseStart = run.recordPhaseTiming("processFunFacts", phaseStart)
+	run.processSetSummary(allPools)
+	run.processTeamSummary(allPools)
+	run.processPickOutliers(db)
+	run.recordPhaseTiming("summaries", phaseStart)
+
+	// Say how this run's reports were made, so that they can be compared with (or reproduced) later
+	run.writeRunManifest(allPools)
+
+	if showTimings {
+		run.printPhaseTimings()
+	}
 
 	// Oh, and for bonus points dump out the day's performance data for the current set
-	//dumpPerfromanceData(db, currentSet)
+	//run.dumpPerfromanceData(db, run.currentSet)
 }
 
-// Open the Google sheet and scrape out the list of pool links from the specific range they live in.
-func getPoolsFromSheet(sheetID, sheetRange, secretFileName string) []PlayerPool {
-	fmt.Println("Processing Sheet: ", sheetID)
-
-	// Open the json secret file that we'll use for auth
-	fmt.Println("Opening secrets file....")
-	data, err := ioutil.ReadFile(secretFileName)
-	checkError(err)
-	conf, err := google.JWTConfigFromJSON(data, sheets.SpreadsheetsScope)
-	checkError(err)
+// Write a json manifest next to the run's reports, with everything needed to tell how they were made
+func (run *Run) writeRunManifest(pools []PlayerPool) {
+	manifest := RunManifest{
+		Version:     getToolVersion(),
+		GeneratedAt: nowFunc().Format(time.RFC3339),
+		CurrentSet:  run.currentSet,
+		SetsInPools: make([]string, 0, len(run.setsInPools)),
+		Config:      make(map[string]string),
+		PerfData:    run.perfFreshness,
+		Pools:       len(pools),
+	}
+	for setCode := range run.setsInPools {
+		manifest.SetsInPools = append(manifest.SetsInPools, setCode)
+	}
+	sort.Strings(manifest.SetsInPools)
+	flag.VisitAll(func(f *flag.Flag) {
+		manifest.Config[f.Name] = f.Value.String()
+	})
+	for _, p := range pools {
+		if p.isAlive {
+			manifest.AlivePools += 1
+		} else {
+			manifest.DeadPools += 1
+		}
+		if !p.hasCardData() {
+			manifest.PoolsWithoutCardData += 1
+		}
+	}
+	for _, ds := range flattenPools(pools) {
+		manifest.UniqueCards += 1
+		manifest.TotalCards += ds.amount
+	}
 
-	// Make a Google Sheets client
-	fmt.Println("Connecting to Google Sheets....")
-	client := conf.Client(context.TODO())
-	srv, err := sheets.New(client)
+	manifestJson, err := json.MarshalIndent(manifest, "", "  ")
 	checkError(err)
-
-	// Read the column with the pool links
-	fmt.Println("Opening sheet....")
-	resp, err := srv.Spreadsheets.Values.Get(sheetID, sheetRange).Do()
+	outputFileName := filepath.Join(run.config.OutputPath, fmt.Sprintf("ASL_%s_manifest.json", getFileTimestamp()))
+	err = ioutil.WriteFile(outputFileName, manifestJson, 0644)
 	checkError(err)
+}
 
-	pools := make([]PlayerPool, 0)
-	if len(resp.Values) == 0 {
-		fmt.Println("No data found.")
-	} else {
-		for _, row := range resp.Values {
-			playerName := fmt.Sprintf("%v", row[sheetPlayerColumnIndex])
-			poolUri := fmt.Sprintf("%v", row[sheetLinkColumnIndex])
-			losses, converr := strconv.Atoi(fmt.Sprintf("%v", row[sheetLossColumnIndex]))
-			checkError(converr)
-			wins, converr := strconv.Atoi(fmt.Sprintf("%v", row[sheetWinColumnIndex]))
-			checkError(converr)
-
-			pools = append(pools, makePool(playerName, "", poolUri, wins, losses))
+// The version of this build: the one stamped in at build time, or else the vcs revision
+func getToolVersion() string {
+	if toolVersion != "" {
+		return toolVersion
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" {
+				return setting.Value
+			}
 		}
+		return info.Main.Version
 	}
+	return "unknown"
+}
 
-	return pools
+// Note how long a phase of the run took (if we're keeping track), and start the clock on the next one
+func (run *Run) recordPhaseTiming(phase string, start time.Time) time.Time {
+	run.phaseTimings = append(run.phaseTimings, PhaseTiming{phase, time.Since(start)})
+	return time.Now()
 }
 
-func populatePools(db *badger.DB, pools []PlayerPool) {
-	// If the list of pools is empty, bail out
-	if len(pools) == 0 {
-		return
+// Print where the run's time went
+func (run *Run) printPhaseTimings() {
+	var total time.Duration
+	fmt.Fprintln(progress, "\nTimings:")
+	for _, pt := range run.phaseTimings {
+		fmt.Fprintf(progress, "  %-22s %8.1fs\n", pt.phase, pt.elapsed.Seconds())
+		total += pt.elapsed
 	}
+	fmt.Fprintf(progress, "  %-22s %8.1fs\n", "total", total.Seconds())
+}
 
-	// For each pool, get the card list
-	for i, pool := range pools {
-		// Call the SealedDeck API and get back the deck
-		var deck = getCardsFromPool(pool.player, pool.uri)
-		pools[i].fetchCardData(db, deck)
+// Read the command line into the package-level options.  Anything left over is a subcommand:
+//
+//	repair: check every cached entry still parses, re-fetching or deleting the ones that don't
+//	warm: fetch the card data for every pool into the cache, and stop there
+//	backfill: re-fetch the card data for every card in the pools, overwriting the cache ("backfill suspect" only re-fetches cards that look wrong)
+//	rebuild-cache: drop the 17lands data that will never be read again, and compact the database
+func parseFlags() {
+	flag.StringVar(&poolsFile, "pools-file", poolsFile, "CSV of Player,Wins,Losses,PoolLink[,Team] to use if the Google sheet can't be read")
+	flag.BoolVar(&onColourStrength, "on-colour-strength", onColourStrength, "Only count cards within an archetype's colours (plus colourless) toward its strength")
+	flag.Float64Var(&qualityWinRateWeight, "quality-wr-weight", qualityWinRateWeight, "Weight (0-1) of GIH WR vs. average pick in the quality score")
+	flag.BoolVar(&curatedListsSkipBasics, "curated-skip-basics", curatedListsSkipBasics, "Ignore basic lands that show up in the curated bomb/dud/top common lists")
+	flag.DurationVar(&webTimeout, "web-timeout", webTimeout, "How long a web request can take before it's given up on and retried (0 to wait forever)")
+	flag.StringVar(&userAgent, "user-agent", userAgent, "User-Agent sent with every web request (scryfall asks for one that identifies the app), e.g. \"AGLStats/1.0 (my-league)\"")
+	flag.Func("store", "Where to cache cards & 17lands data between runs: badger (default), or sqlite for a single <dbPath>.sqlite file with a kv table", func(value string) error {
+		if value != "badger" && value != "sqlite" {
+			return errors.New("store must be badger or sqlite")
+		}
+		cardStoreKind = value
+		return nil
+	})
+	flag.IntVar(&poolConcurrency, "concurrency", poolConcurrency, "How many pools to fetch at once (each site's rate limit still applies)")
+	flag.IntVar(&maxConcurrentRequests, "max-requests", maxConcurrentRequests, "Maximum web requests in flight at once, across all sites")
+	flag.BoolVar(&perPoolOutput, "per-pool-output", perPoolOutput, "Also write each pool's facts and cards to its own json file")
+	flag.BoolVar(&currentSetOnlyStrength, "current-set-strength", currentSetOnlyStrength, "Only count cards printed in the current set toward strength")
+	flag.IntVar(&expectedPlayers, "expected-players", expectedPlayers, "Warn if the sheet doesn't have about this many players (0 to skip)")
+	flag.Func("perf-blend", "Blend the win rates of several 17lands formats, e.g. PremierDraft:0.7,TradDraft:0.3", func(value string) (err error) {
+		setPerformanceBlend, err = parseFormatWeights(value)
+		return err
+	})
+	flag.StringVar(&explainPlayer, "explain", explainPlayer, "Print out how the named player's strength was computed")
+	flag.IntVar(&sheetStatusColumnIndex, "status-column", sheetStatusColumnIndex, "Index of an alive/dead status column in the sheet range, which overrides the loss count (-1 for none)")
+	flag.IntVar(&sheetTeamColumnIndex, "team-column", sheetTeamColumnIndex, "Index of a team column in the sheet range, for team leagues (-1 for none)")
+	flag.StringVar(&archetypesFile, "archetypes", archetypesFile, "JSON file of the 2 and 3 colour archetypes for each set")
+	flag.BoolVar(&cachedOnly, "cached-only", cachedOnly, "Only use card data that's already in the database (no scryfall lookups)")
+	flag.StringVar(&funFactsOutput, "out", funFactsOutput, "Where to write the fun facts (\"-\" for stdout)")
+	flag.Func("format", "Output format(s) for the fun facts: csv, json or jsonl (one json object per line), comma-separated for several, e.g. csv,json", func(value string) error {
+		outputFormats = make([]string, 0)
+		for _, format := range strings.Split(value, ",") {
+			format = strings.ToLower(strings.TrimSpace(format))
+			if format != "csv" && format != "json" && format != "jsonl" {
+				return errors.New("format must be csv, json or jsonl (or a comma-separated list of them)")
+			}
+			if !containsString(outputFormats, format) {
+				outputFormats = append(outputFormats, format)
+			}
+		}
+		return nil
+	})
+	flag.BoolVar(&maindeckOnlyStrength, "maindeck-strength", maindeckOnlyStrength, "Compute strength from the main deck only, rather than the whole pool")
+	flag.StringVar(&ratingsFile, "ratings-file", ratingsFile, "CSV of CardName,Rating (0-100 or A+ to F) to compute strength from instead of 17lands")
+	flag.Func("injected-cards", "Comma-separated cards that sealeddeck.tech injects into pools, ignored like basic lands (default \"Command Tower\", empty to count them)", func(value string) error {
+		injectedCardNames = make([]string, 0)
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				injectedCardNames = append(injectedCardNames, name)
+			}
+		}
+		return nil
+	})
+	flag.Func("set", "Set code to analyze, overriding the config's currentSet (one of the sets 17lands has data for)", func(value string) error {
+		value = strings.ToUpper(strings.TrimSpace(value))
+		if !containsString(allSeventeenLandsSets, value) {
+			return errors.New(fmt.Sprintf("%s isn't a set we have 17lands data for.  Valid sets: %s", value, strings.Join(allSeventeenLandsSets, ", ")))
+		}
+		setCodeOverride = value
+		return nil
+	})
+	flag.StringVar(&setPerformanceFormat, "perf-format", setPerformanceFormat, "17lands format to pull win rates from, e.g. PremierDraft, TradDraft or Sealed (-format is the output format)")
+	flag.StringVar(&sheetIdOverride, "sheet", sheetIdOverride, "ID of the league's google sheet, overriding the config's leagueSheetID")
+	flag.StringVar(&configFile, "config", configFile, "JSON file with the paths (googleApiSecretFile, dbPath, outputPath, perfOutputPath) and league settings (leagueSheetID, poolLinkRange, currentSet)")
+	flag.Float64Var(&playableWinRate, "playable-wr", playableWinRate, "GIH WR (%) a card needs to count as playable, for the share of the pool that's playable")
+	flag.Func("watch-cards", "Comma-separated cards to track from run to run: how many living & dead pools have them, and how those pools are doing", func(value string) error {
+		watchCardNames = make([]string, 0)
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				watchCardNames = append(watchCardNames, name)
+			}
+		}
+		return nil
+	})
+	flag.Float64Var(&curvePenalty, "curve-penalty", curvePenalty, "Add a curve-adjusted strength where each 5+ mana card past a fifth of an archetype's cards counts this much less (0-1, 0 to skip it)")
+	flag.BoolVar(&offlineSheet, "offline-sheet", offlineSheet, "Read the pools from the last copy of the sheet cached in the database (no google calls, and the stats tab isn't written)")
+	flag.Float64Var(&contestedColourWeight, "contested-colours", contestedColourWeight, "Mark down the strength of cards in colours the living pools are over-drafting (0 for off, 1 to knock 10% off a card whose colour is 10% over its fair share)")
+	flag.BoolVar(&showTimings, "timings", showTimings, "Print how long each phase of the run took")
+	flag.BoolVar(&detectCurrentSet, "detect-set", detectCurrentSet, "Work out the current set from the sets the pools' cards are from")
+	flag.Func("detect-set-ignore", "Comma-separated set codes that never count when detecting the current set (default \"PLST,SLD\"); non-draftable set types never count", func(value string) error {
+		detectSetIgnoredCodes = make([]string, 0)
+		for _, setCode := range strings.Split(value, ",") {
+			if setCode = strings.ToUpper(strings.TrimSpace(setCode)); setCode != "" {
+				detectSetIgnoredCodes = append(detectSetIgnoredCodes, setCode)
+			}
+		}
+		return nil
+	})
+	flag.Float64Var(&efficientPlayableWinRate, "efficient-wr", efficientPlayableWinRate, "GIH WR (%) a card at 3 or less mana needs to count as an efficient playable")
+	flag.BoolVar(&compareLatest, "compare-latest", compareLatest, "Print the strength and record changes since the most recent earlier fun facts csv")
+	flag.Func("derived-bombs", "Derive the bombs from 17lands instead of the curated list, with a GIH WR floor per rarity, e.g. common:58,uncommon:60,rare:62,mythic:62", func(value string) (err error) {
+		derivedBombFloors, err = parseRarityFloors(value)
+		return err
+	})
+	flag.BoolVar(&addPackDiff, "add-pack-diff", addPackDiff, "For players with a history of pool links (oldest first), list what changed since their previous pool")
+	flag.BoolVar(&masterWinRates, "master-wr", masterWinRates, "Add each card's best GIH WR (across the archetypes) to the master card files")
+	flag.Func("dead-pools", "Dead pools in the fun facts: include, exclude, or separate (their own file)", func(value string) error {
+		if value != "include" && value != "exclude" && value != "separate" {
+			return errors.New("dead-pools must be include, exclude or separate")
+		}
+		deadPoolsOutput = value
+		return nil
+	})
+	flag.Func("pool-type", "What kind of pools the league has, which sizes the strength calculation: sealed, draft or auto (guess from each pool's size)", func(value string) error {
+		if value != "sealed" && value != "draft" && value != "auto" {
+			return errors.New("pool-type must be sealed, draft or auto")
+		}
+		leaguePoolType = value
+		return nil
+	})
+	flag.StringVar(&legalityFormat, "legal-format", legalityFormat, "Flag pools with cards that are banned or not legal in this format, e.g. standard or historic (empty to skip)")
+	flag.IntVar(&factCardsCap, "fact-cards", factCardsCap, "Only count each pool's N strongest cards toward the colour, mana value and card type facts (0 for the whole pool)")
+	flag.StringVar(&statsSheetName, "stats-sheet", statsSheetName, "Tab in the league sheet to copy the fun facts into, created if needed, or a range in it like Stats!B2:AZ to leave the rest of the tab alone (empty to skip)")
+	flag.DurationVar(&priceRefreshInterval, "price-refresh", priceRefreshInterval, "Refresh cached card prices from scryfall when they're older than this (0 to never)")
+	flag.DurationVar(&cardTTL, "card-ttl", cardTTL, "Re-fetch a cached card from scryfall when it's older than this, e.g. 168h (0 to keep cached cards forever)")
+	flag.Parse()
+
+	// Stdout can only take one report
+	if funFactsOutput == "-" && len(outputFormats) > 1 {
+		checkError(errors.New("Only one -format can be written to stdout"))
 	}
-}
 
-// Connect to SealedDeck.tech and grab the card list for a given pool
-func getCardsFromPool(name string, uri string) *SealedDeck {
-	fmt.Printf("Fetching pool for %s from: %s\n", name, uri)
-	rawJson, err := getWebResponseString(uri, sealedDeckPauseMs)
-	checkError(err)
+	// Keep stdout clean for the report, so that it can be piped somewhere
+	if funFactsOutput == "-" {
+		progress = os.Stderr
+	}
 
-	// Convert the json to our deck struct
-	sealedDeck := new(SealedDeck)
-	json.Unmarshal([]byte(rawJson), &sealedDeck)
+	webScheduler = makeWebScheduler()
+	httpClient = makeHttpClient(makeHttpTransport(), webTimeout)
+	downloadHttpClient = makeHttpClient(makeHttpTransport(), 0)
+}
 
-	// take a nap to not hammer the site
-	time.Sleep(sealedDeckPauseMs * time.Millisecond)
+// Build the web scheduler, with each site's pause as its minimum interval between requests
+func makeWebScheduler() *RequestScheduler {
+	return makeRequestScheduler(maxConcurrentRequests, map[string]time.Duration{
+		getHost(fmt.Sprintf(sealedDeckApiUriTemplate, "")): sealedDeckPauseMs * time.Millisecond, // the template's path doesn't parse until it's filled in
+		getHost(scryfallCardTemplate):                      scryfallPauseMs * time.Millisecond,
+		getHost(seventeenLandsTemplate):                    seventeenLandsPauseMs * time.Millisecond,
+	})
+}
 
-	return sealedDeck
+// Where everything lives when there's no config: relative to wherever we're run from, for the original league
+func defaultConfig() Config {
+	return Config{
+		GoogleApiSecretFile: "asl-pools-859d88f87aef.json",
+		DbPath:              "db",
+		OutputPath:          "out",
+		PerfOutputPath:      "out-perf",
+		LeagueSheetID:       "1cNoZe15TjOgmtTsbH1R3nX_YU9Q9E224bjVUEV0haDk",
+		PoolLinkRange:       "Pools!A7:H67",
+		CurrentSet:          "HBG",
+	}
 }
 
-// For a given deck, get a flattened and enriched set of card data and shove it into the supplied slice
-func (pool *PlayerPool) fetchCardData(db *badger.DB, deck *SealedDeck) {
+// Read the config file.  The paths fall back to the relative defaults, but the league settings have to be there.
+// A missing file is only an error if it was asked for - otherwise everything comes from the defaults.
+func loadConfig(fileName string, required bool) (Config, error) {
+	data, err := ioutil.ReadFile(fileName)
+	if os.IsNotExist(err) && !required {
+		fmt.Fprintln(progress, "No config file, using the defaults")
+		return defaultConfig(), nil
+	}
+	if err != nil {
+		return Config{}, errors.New(fmt.Sprintf("Could not read the config file %s: %v", fileName, err))
+	}
 
-	// Flatten the deck into a series of cards
-	allCards := deck.flatten()
+	var loaded Config
+	err = json.Unmarshal(data, &loaded)
+	if err != nil {
+		return Config{}, errors.New(fmt.Sprintf("The config file %s isn't valid json: %v", fileName, err))
+	}
 
-	// Now populate the card data from the database (if we've seen it before) or scryfall
-	for _, card := range allCards {
-		resultCard, err := getCard(db, card.cardName)
-		checkError(err)
-		pool.cards = append(pool.cards, DeckSlot{amount: card.amount, cardName: resultCard.Name, card: resultCard}) // use the result card name due to casing problems in sealeddeck.tech
+	// Paths can be left out
+	defaults := defaultConfig()
+	if loaded.DbPath == "" {
+		loaded.DbPath = defaults.DbPath
+	}
+	if loaded.OutputPath == "" {
+		loaded.OutputPath = defaults.OutputPath
+	}
+	if loaded.PerfOutputPath == "" {
+		loaded.PerfOutputPath = defaults.PerfOutputPath
+	}
 
-		if !leagueIsMonoSet {
-			setsInPools[strings.ToUpper(resultCard.Set)] = 1
+	// But the rest can't
+	missing := make([]string, 0)
+	for name, value := range map[string]string{"googleApiSecretFile": loaded.GoogleApiSecretFile, "leagueSheetID": loaded.LeagueSheetID, "poolLinkRange": loaded.PoolLinkRange, "currentSet": loaded.CurrentSet} {
+		if strings.TrimSpace(value) == "" {
+			missing = append(missing, name)
 		}
 	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return Config{}, errors.New(fmt.Sprintf("The config file %s is missing: %s", fileName, strings.Join(missing, ", ")))
+	}
+
+	return loaded, nil
 }
 
-// For a batch of pools, gather all the card data and dump it to a file.
-func processPools(db *badger.DB, pools []PlayerPool, poolType string) {
+// Was the flag given on the command line (rather than left at its default)?
+func isFlagSet(name string) bool {
+	var set = false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
 
-	// If the list of pools is empty, bail out
-	if len(pools) == 0 {
-		return
+// Grab all of the pools in the google sheet, falling back to a local file if we can't get at the sheet
+func (run *Run) getAllPools(db CardStore) []PlayerPool {
+	allPools, err := run.getPoolsFromSheet(db, run.config.LeagueSheetID, run.config.PoolLinkRange, run.config.GoogleApiSecretFile) //[0:1]
+	if err != nil {
+		if poolsFile == "" {
+			checkError(err)
+		}
+		fmt.Fprintln(progress, err)
+		fmt.Fprintln(progress, "Falling back to the pools file: ", poolsFile)
+		allPools, err = getPoolsFromFile(poolsFile)
+		checkError(err)
 	}
+	checkPlayerCount(allPools, run.config.PoolLinkRange)
+	return allPools
+}
 
-	// Make a master list of all of the cards across the set of pools
-	allCards := make(map[string]DeckSlot)
-	for _, pool := range pools {
-		// Append the cards from the pool to the master list
-		flattenDeckSlots(allCards, pool.cards)
+// Open the Google sheet and scrape out the list of pool links from the specific range they live in.
+func (run *Run) getPoolsFromSheet(db CardStore, sheetID, sheetRange, secretFileName string) ([]PlayerPool, error) {
+	fmt.Fprintln(progress, "Processing Sheet: ", sheetID)
+
+	rows, err := run.getSheetRows(db, sheetID, sheetRange, secretFileName)
+	if err != nil {
+		return nil, err
 	}
 
-	// Write out a tab-delimited file for easy analysis
-	outputFileName := fmt.Sprintf("%s\\ASL_%d_%d_%d_%d_%d_%s.txt", outputPath, time.Now().Year(), time.Now().Month(), time.Now().Day(), time.Now().Hour(), time.Now().Minute(), poolType)
-	outputFile, err := os.Create(outputFileName)
-	checkError(err)
-	writer := bufio.NewWriter(outputFile)
+	pools := make([]PlayerPool, 0)
+	if len(rows) == 0 {
+		fmt.Fprintln(progress, "No data found.")
+	} else {
+		// The sheets api drops trailing empty cells, so a player who hasn't posted a pool yet shows up as a short row
+		var requiredColumns = 0
+		for _, index := range []int{sheetPlayerColumnIndex, sheetWinColumnIndex, sheetLossColumnIndex, sheetLinkColumnIndex} {
+			if index+1 > requiredColumns {
+				requiredColumns = index + 1
+			}
+		}
+		skipped := make([]string, 0)
+		for i, row := range rows {
+			var playerName = fmt.Sprintf("(row %d)", i+1)
+			if sheetPlayerColumnIndex < len(row) && getCellString(row[sheetPlayerColumnIndex]) != "" {
+				playerName = getCellString(row[sheetPlayerColumnIndex])
+			}
+			if len(row) < requiredColumns || getCellString(row[sheetLinkColumnIndex]) == "" {
+				skipped = append(skipped, playerName)
+				continue
+			}
 
-	writer.WriteString("Name	Set	Rarity	ManaCost	TypeLine	PriceUSD	Amount\n")
-	for _, ds := range allCards {
-		theCard := ds.card
-		writer.WriteString(fmt.Sprintf("%s	%s	%s	%s	%s	%s	%d\n", theCard.Name, theCard.Set, theCard.Rarity, theCard.getManaCost(), theCard.getTypeLineClean(), theCard.Prices.Usd, ds.amount))
-	}
-	writer.Flush()
-}
+			poolUri := getCellString(row[sheetLinkColumnIndex])
+			losses, lossErr := getCellInt(row[sheetLossColumnIndex])
+			wins, winErr := getCellInt(row[sheetWinColumnIndex])
+			if lossErr != nil || winErr != nil {
+				fmt.Fprintf(progress, "Skipping %s, who has a bad record in the sheet: %s-%s\n", playerName, getCellString(row[sheetWinColumnIndex]), getCellString(row[sheetLossColumnIndex]))
+				skipped = append(skipped, playerName)
+				continue
+			}
 
-// Place all cards into allCards.
-// Rules:
-// 1. If we haven't seen the card before, make a new entry for it
-// 2. If we have seen the card before, add the copies to the existing entry
-func (deck *SealedDeck) flatten() map[string]DeckSlot {
-	// Append the deck & sideboard into one list
-	var allCards = append(deck.Deck, deck.Sideboard...)
+			pool := makePool(playerName, getRowTeam(row, sheetTeamColumnIndex), poolUri, wins, losses)
+			if sheetStatusColumnIndex >= 0 && sheetStatusColumnIndex < len(row) {
+				pool.applyStatus(getCellString(row[sheetStatusColumnIndex]))
+			}
+			pools = append(pools, pool)
+		}
 
-	// Add all cards from the main deck
-	flattenedCards := make(map[string]DeckSlot)
-	for _, card := range allCards {
-		value, ok := flattenedCards[card.Name]
-		if ok {
-			flattenedCards[card.Name] = DeckSlot{amount: value.amount + card.Count, cardName: card.Name}
-		} else {
-			flattenedCards[card.Name] = DeckSlot{amount: card.Count, cardName: card.Name}
+		if len(skipped) > 0 {
+			fmt.Fprintf(progress, "Skipped %d sheet rows without a pool link or record: %s\n", len(skipped), strings.Join(skipped, ", "))
 		}
 	}
 
-	return flattenedCards
+	return pools, nil
 }
 
-// Place all cards into allCards.
-// Rules:
-// 1. If we haven't seen the card before, make a new entry for it
-// 2. If we have seen the card before, add the copies to the existing entry
-func flattenDeckSlots(allCards map[string]DeckSlot, cards []DeckSlot) {
-	// Add all cards from the main deck
-	for _, c := range cards {
-		value, ok := allCards[c.cardName]
-		if ok {
-			allCards[c.cardName] = DeckSlot{amount: value.amount + c.amount, cardName: c.cardName, card: c.card}
-		} else {
-			allCards[c.cardName] = DeckSlot{amount: c.amount, cardName: c.cardName, card: c.card}
+// The team in a row's team column.  Leagues without a team column (-1) have no teams at all, while in a team league a blank team cell is
+// unaffiliated - and so is a missing one, since the sheets api drops trailing empty cells.
+func getRowTeam(row []interface{}, teamColumnIndex int) string {
+	if teamColumnIndex < 0 {
+		return ""
+	}
+	if teamColumnIndex < len(row) {
+		if team := getCellString(row[teamColumnIndex]); team != "" {
+			return team
 		}
 	}
+	return unaffiliatedTeamName
 }
 
-// Get the call from the database, or if it's not already there, pull it from scryfall instead.
-// Note: be a good citizen to scryfall, and pause after getting the card
-func getCard(db *badger.DB, cardName string) (resultCard *ScryfallCard, err error) { // TODO: Add the card type to the return value
-
-	cardJson := ""
-	card := new(ScryfallCard)
-
-	// Force all card names to lower case (for some sealeddeck oddities) and then remove the Alchemy designation from cards
-	cardName = strings.ToLower(cardName)
-	if strings.HasPrefix(cardName, "a-") {
-		cardName = strings.Trim(cardName, "a-")
+// Read the rows in a range of the sheet, keeping a copy in the database.  With -offline-sheet the copy is used instead, so no google calls are made.
+func (run *Run) getSheetRows(db CardStore, sheetID, sheetRange, secretFileName string) ([][]interface{}, error) {
+	if offlineSheet {
+		return getCachedSheetRows(db, sheetID, sheetRange)
 	}
 
-	// First try to get the card from the database
-	cardJson, err = dbGet(db, cardName)
+	srv, err := run.getSheetsService(secretFileName)
 	if err != nil {
-		// If the db lookup failed, try to get the card from scryfall
-		cardJson, err = scryfallGet(cardName)
-		if err != nil {
-			return card, errors.New(fmt.Sprintf("Could not find card in db or in scryfall: %s", cardName))
-		}
+		return nil, err
+	}
 
-		// Store it in the database for next time
-		err = dbSet(db, cardName, cardJson)
-		checkError(err)
+	// Read the column with the pool links.  This is the first call that actually authenticates, so an expired key or unshared sheet shows up here.
+	fmt.Fprintln(progress, "Opening sheet....")
+	resp, err := srv.Spreadsheets.Values.Get(sheetID, sheetRange).Do()
+	if err != nil {
+		return nil, run.sheetsAuthError(err)
 	}
 
-	// Return the card
-	json.Unmarshal([]byte(cardJson), &card)
-	return card, nil
-}
+	// Keep today's copy, and the latest copy, for offline runs
+	rowsJson, err := json.Marshal(resp.Values)
+	checkError(err)
+	checkError(dbSet(db, getSheetDbKey(sheetID, sheetRange, true), string(rowsJson)))
+	checkError(dbSet(db, getSheetDbKey(sheetID, sheetRange, false), string(rowsJson)))
+	checkError(dbSet(db, getSheetDbKey(sheetID, sheetRange, false)+"_fetched", nowFunc().Format(time.RFC3339)))
 
-func scryfallGet(cardName string) (resultJson string, err error) {
-	fmt.Println("Fetching card from Scryfall: ", cardName)
+	return resp.Values, nil
+}
 
-	// We have a baseUri which fetches the card from whichever set scryfall fancies, and then a setUri that gets the card from the current set.
-	// We want to try the current set to get the specifics for a card, and if that fails, fallback to the base uri.
-	var baseUri string = fmt.Sprintf(scryfallCardTemplate, url.QueryEscape(cardName))
-	var setUri string = baseUri + fmt.Sprintf(scryfallSetClauseTemplate, url.QueryEscape(currentSet))
+// Read the rows of a range from the copy of the sheet in the database: today's if there is one, otherwise the most recent
+func getCachedSheetRows(db CardStore, sheetID, sheetRange string) ([][]interface{}, error) {
+	rowsJson, err := dbGet(db, getSheetDbKey(sheetID, sheetRange, true))
+	if err != nil || strings.TrimSpace(rowsJson) == "" {
+		rowsJson, err = dbGet(db, getSheetDbKey(sheetID, sheetRange, false))
+		if err != nil || strings.TrimSpace(rowsJson) == "" {
+			return nil, errors.New(fmt.Sprintf("No cached copy of %s in sheet %s.  Run once without -offline-sheet to cache it.", sheetRange, sheetID))
+		}
+		fetchedAt, _ := dbGet(db, getSheetDbKey(sheetID, sheetRange, false)+"_fetched")
+		fmt.Fprintln(progress, "No copy of the sheet from today, using the one cached at: ", fetchedAt)
+	}
 
-	var rawJson string = ""
-	rawJson, err = getWebResponseString(setUri, scryfallPauseMs)
+	var rows [][]interface{}
+	err = json.Unmarshal([]byte(rowsJson), &rows)
 	if err != nil {
-		rawJson, err = getWebResponseString(baseUri, scryfallPauseMs)
-		if err != nil {
-			fmt.Println("Error fetching card from scryfall: ", err)
-		}
+		return nil, errors.New(fmt.Sprintf("The cached copy of %s in sheet %s is corrupt: %v", sheetRange, sheetID, err))
 	}
+	fmt.Fprintln(progress, "Using the cached copy of the sheet")
+	return rows, nil
+}
 
-	// And then wait for a few ms to be a good citizen
-	time.Sleep(scryfallPauseMs * time.Millisecond)
+// Build the key for a cached copy of a range of the sheet, either today's copy or the latest one
+func getSheetDbKey(sheetID, sheetRange string, today bool) string {
+	var dateKey = ""
+	if today {
+		dateKey = "_" + nowFunc().Format("2006_01_02")
+	}
+	return fmt.Sprintf("sheet_%s_%s%s", sheetID, sheetRange, dateKey)
+}
 
-	return rawJson, err
+// Turn a sheet cell into a string.  Depending on how the sheet renders values, numbers can come back as float64s.
+func getCellString(cell interface{}) string {
+	switch v := cell.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64) // 5 rather than 5.000000 or 5e+00
+	case nil:
+		return ""
+	default:
+		return strings.TrimSpace(fmt.Sprintf("%v", v))
+	}
 }
 
-// Load all deck card performance data for all decks
-func loadCardPerformanceData(db *badger.DB) map[string]map[string]float64 {
+// Turn a sheet cell into a whole number, whether it came back as a number or a string like "5" or "5.0"
+func getCellInt(cell interface{}) (int, error) {
+	switch v := cell.(type) {
+	case float64:
+		return int(math.Round(v)), nil
+	case int:
+		return v, nil
+	}
 
-	var cpByDeck = make(map[string]map[string]float64)
+	s := getCellString(cell)
+	if i, err := strconv.Atoi(s); err == nil {
+		return i, nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || f != math.Trunc(f) {
+		return 0, errors.New(fmt.Sprintf("Expected a whole number in the sheet, got: %s", s))
+	}
+	return int(f), nil
+}
 
-	// Walk the sets in order, and process the ones that we detect cards for
-	for _, setCode := range allSeventeenLandsSets {
-		if setsInPools[setCode] == 1 {
-			fmt.Println("Fetching card performance data for ", setCode)
+// Sanity check the number of pools we found.  A count that's way off usually means the range is wrong or the sheet was edited.
+func checkPlayerCount(pools []PlayerPool, sheetRange string) {
+	if expectedPlayers > 0 {
+		var diff = len(pools) - expectedPlayers
+		if diff > expectedPlayerTolerance || diff < -expectedPlayerTolerance {
+			fmt.Fprintf(progress, "WARNING: Expected about %d players, but found %d.  Check the sheet range (%s)!\n", expectedPlayers, len(pools), sheetRange)
+		}
+	}
 
-			// Grab 17lands perf data for this set
-			// Note: If a specific card is in multiple sets, we grab the latest
-			for _, deckId := range getDecks(setCode) {
-				cp, err := getCardPerformanceData(db, setCode, deckId, false)
-				
-				// Shoot - we couldn't get perf data for this card.  Skip it for now?
+	// If every row in the range is used, there could be more players below it that we never saw
+	if rows := getRangeRowCount(sheetRange); rows > 0 && len(pools) >= rows {
+		fmt.Fprintf(progress, "WARNING: Every row of %s has a player in it.  Players past the end of the range are being dropped!\n", sheetRange)
+	}
+}
+
+// How many rows are in a range like "Pools!A7:H67", or 0 if we can't tell
+func getRangeRowCount(sheetRange string) int {
+	matches := regexp.MustCompile(`[A-Z]+(\d+):[A-Z]+(\d+)$`).FindStringSubmatch(sheetRange)
+	if matches == nil {
+		return 0
+	}
+	first, _ := strconv.Atoi(matches[1])
+	last, _ := strconv.Atoi(matches[2])
+	return last - first + 1
+}
+
+// Get an authenticated Google Sheets client.  The client is made once and then shared by everything that talks to sheets.
+func (run *Run) getSheetsService(secretFileName string) (*sheets.Service, error) {
+	if sheetsService != nil {
+		return sheetsService, nil
+	}
+
+	// Open the json secret file that we'll use for auth
+	fmt.Fprintln(progress, "Opening secrets file....")
+	data, err := ioutil.ReadFile(secretFileName)
+	if err != nil {
+		return nil, run.sheetsAuthError(err)
+	}
+	conf, err := google.JWTConfigFromJSON(data, sheets.SpreadsheetsScope)
+	if err != nil {
+		return nil, run.sheetsAuthError(err)
+	}
+
+	// Make a Google Sheets client
+	fmt.Fprintln(progress, "Connecting to Google Sheets....")
+	client := conf.Client(context.TODO())
+	srv, err := sheets.New(client)
+	if err != nil {
+		return nil, run.sheetsAuthError(err)
+	}
+
+	sheetsService = srv
+	return sheetsService, nil
+}
+
+// Wrap up a google error with a hint about what usually causes it
+func (run *Run) sheetsAuthError(err error) error {
+	return fmt.Errorf("Google Sheets auth failed: check the service account file (%s) and that the sheet is shared with it: %w", run.config.GoogleApiSecretFile, err)
+}
+
+// Read the pools from a local csv (Player,Wins,Losses,PoolLink and optionally Team) instead of the google sheet.  A header row is skipped.
+func getPoolsFromFile(fileName string) ([]PlayerPool, error) {
+	fmt.Fprintln(progress, "Reading pools from file: ", fileName)
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	// The team column is optional, but the csv reader makes every line as long as the first one
+	var teamColumnIndex = -1
+	if len(rows) > 0 && len(rows[0]) > fileTeamColumnIndex {
+		teamColumnIndex = fileTeamColumnIndex
+	}
+
+	pools := make([]PlayerPool, 0)
+	for i, row := range rows {
+		if len(row) < 4 {
+			return nil, errors.New(fmt.Sprintf("Line %d of %s needs four columns: Player,Wins,Losses,PoolLink", i+1, fileName))
+		}
+		wins, winErr := strconv.Atoi(strings.TrimSpace(row[1]))
+		losses, lossErr := strconv.Atoi(strings.TrimSpace(row[2]))
+		if winErr != nil || lossErr != nil {
+			if i == 0 {
+				continue // header
+			}
+			return nil, errors.New(fmt.Sprintf("Line %d of %s has a bad record: %s-%s", i+1, fileName, row[1], row[2]))
+		}
+
+		cells := make([]interface{}, len(row))
+		for j, cell := range row {
+			cells[j] = cell
+		}
+		pools = append(pools, makePool(strings.TrimSpace(row[0]), getRowTeam(cells, teamColumnIndex), strings.TrimSpace(row[3]), wins, losses))
+	}
+
+	return pools, nil
+}
+
+// One bad pool shouldn't sink the whole report: a pool that can't be fetched is dropped (and returned with the error), and a card that
+// can't be looked up is left out of its pool.  Everything that went wrong gets summarized at the end.
+func (run *Run) populatePools(db CardStore, pools []PlayerPool) (populated []PlayerPool, failures []error) {
+	// If the list of pools is empty, bail out
+	if len(pools) == 0 {
+		return pools, nil
+	}
+
+	// Fetch the pools a few at a time.  Each worker only touches its own pools, so the results can be gathered up in order afterward.
+	skipped := make([]bool, len(pools))
+	poolErrors := make([]error, len(pools))
+	var workers = poolConcurrency
+	if workers < 1 {
+		workers = 1
+	}
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				// Call the SealedDeck API and get back the deck
+				deck, err := getCardsFromPool(pools[i].player, pools[i].uri)
 				if err != nil {
+					fmt.Fprintf(progress, "Skipping %s's pool: %v\n", pools[i].player, err)
+					skipped[i] = true
+					poolErrors[i] = errors.New(fmt.Sprintf("%s: pool skipped: %v", pools[i].player, err))
 					continue
 				}
-
-				// Extract the GIH_WR
-				var gihByCard = make(map[string]float64)
-				for _, cardData := range cp {
-					if cardData.EverDrawnGameCount > getCardPrevalenceThreshold(cardData.Rarity) {
-						gihByCard[cardData.Name] = cardData.EverDrawnWinRate
-					} else { // filter out rarely played cards
-						gihByCard[cardData.Name] = 0
-					}
+				err = pools[i].fetchCardData(run, db, deck)
+				if err != nil {
+					fmt.Fprintf(progress, "Some of %s's cards were left out: %v\n", pools[i].player, err)
+					poolErrors[i] = errors.New(fmt.Sprintf("%s: %v", pools[i].player, err))
 				}
+			}
+		}()
+	}
+	for i := range pools {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
 
-				cpByDeck[deckId] = gihByCard
-			} // end for
-		} // end if
-	} // end for
+	populated = make([]PlayerPool, 0, len(pools))
+	for i := range pools {
+		if poolErrors[i] != nil {
+			failures = append(failures, poolErrors[i])
+		}
+		if !skipped[i] {
+			populated = append(populated, pools[i])
+		}
+	}
 
-	return cpByDeck
+	if len(failures) > 0 {
+		fmt.Fprintf(progress, "\nWARNING: %d of %d pools had problems:\n", len(failures), len(pools))
+		for _, failure := range failures {
+			fmt.Fprintln(progress, "  ", failure)
+		}
+	}
+	return populated, failures
 }
 
-// Get the call from the database, or if it's not already there, pull it from 17lands.com instead.
-func getCardPerformanceData(db *badger.DB, setCode string, deckId string, forceDataRefresh bool) (resultCard CardPerformance, err error) {
-	rawJson := ""
-	cp := new(CardPerformance)
+// Connect to SealedDeck.tech and grab the card list for a given pool
+func getCardsFromPool(name string, uri string) (*SealedDeck, error) {
+	fmt.Fprintf(progress, "Fetching pool for %s from: %s\n", name, uri)
+	rawJson, err := getWebResponseString(uri, sealedDeckPauseMs)
+	if err != nil {
+		return nil, err
+	}
 
-	// Build the key to access the set perf data.  If the set is the current one we'll refresh daily.  Otherwise, we rely on cached data
-	var dateKey = ""
-	if setCode == currentSet {
-		dateKey = fmt.Sprintf("_%d_%d_%d", time.Now().Year(), time.Now().Month(), time.Now().Day())
+	// Convert the json to our deck struct
+	sealedDeck := new(SealedDeck)
+	err = json.Unmarshal([]byte(rawJson), &sealedDeck)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Could not read the pool from %s: %v", uri, err))
 	}
-	var dbKey = fmt.Sprintf("17lands_%s_%s%s", setCode, deckId, dateKey)
 
-	// Try to get the card from the database
-	rawJson, err = dbGet(db, dbKey)
-	if err != nil || strings.TrimSpace(rawJson) == "" || forceDataRefresh {
-		// If the db lookup failed, try to get the data from 17lands
-		rawJson, err = seventeenLandsGet(setCode, deckId)
+	return sealedDeck, nil
+}
+
+// For a given deck, get a flattened and enriched set of card data and shove it into the supplied slice.
+// Cards that can't be looked up are counted as missing and left out, and named in the returned error.
+func (pool *PlayerPool) fetchCardData(run *Run, db CardStore, deck *SealedDeck) error {
+
+	// Flatten the deck into a series of cards
+	allCards := deck.flatten()
+
+	// Grab the cards we haven't seen before (or that are past their TTL) from scryfall in bulk, so that the lookups below mostly hit the database.
+	// Cards already looked up this run are in memory, so they don't need the database at all.
+	if !cachedOnly {
+		uncachedNames := make([]string, 0)
+		for _, card := range allCards {
+			key := getCardDbKey(card.cardName)
+			if run.isMemoizedCard(key) {
+				continue
+			}
+			if isCardFetchNeeded(db, key) {
+				uncachedNames = append(uncachedNames, card.cardName)
+			}
+		}
+		if len(uncachedNames) > 0 {
+			if _, err := run.scryfallGetBatch(db, uncachedNames); err != nil {
+				fmt.Fprintln(progress, "Error fetching cards from scryfall in bulk, looking them up one at a time: ", err)
+			}
+		}
+	}
+
+	// Now populate the card data from the database (if we've seen it before) or scryfall
+	var failedCards = make([]string, 0)
+	for _, card := range allCards {
+		resultCard, err := run.getCard(db, card.cardName, false)
 		if err != nil {
-			return *cp, errors.New(fmt.Sprintf("Could not find card perf data in db or on 17lands.com: %s", deckId))
+			// Offline, a card we've never seen just can't be looked up.  Keep track so that we don't pretend the pool is empty.
+			pool.missingCards += 1
+			if !cachedOnly {
+				failedCards = append(failedCards, card.cardName)
+			}
+			continue
 		}
+		pool.cards = append(pool.cards, DeckSlot{amount: card.amount, deckAmount: card.deckAmount, cardName: resultCard.Name, card: resultCard}) // use the result card name due to casing problems in sealeddeck.tech
 
-		// Store it in the database for next time
-		err = dbSet(db, dbKey, rawJson)
-		checkError(err)
+		if !leagueIsMonoSet {
+			run.setsInPoolsMu.Lock()
+			run.setsInPools[strings.ToUpper(resultCard.Set)] = 1
+			run.setsInPoolsMu.Unlock()
+		}
 	}
 
-	// Return the card
-	json.Unmarshal([]byte(rawJson), &cp)
-	return *cp, nil
+	if len(failedCards) > 0 {
+		sort.Strings(failedCards)
+		return errors.New(fmt.Sprintf("Could not look up %d card(s): %s", len(failedCards), strings.Join(failedCards, "; ")))
+	}
+	return nil
 }
 
-func seventeenLandsGet(setCode string, deckId string) (resultJson string, err error) {
-	fmt.Println("Fetching card performance data from 17lands.com: ", deckId)
+// Call out the pools that we couldn't find any card data for, so that nobody mistakes their all-zero stats for a real (empty) pool
+func reportPoolsWithoutCardData(pools []PlayerPool) {
+	for _, p := range pools {
+		if !p.hasCardData() {
+			fmt.Fprintf(progress, "WARNING: No card data available for %s's pool (%d cards missing from the cache).  Its stats will be empty.\n", p.player, p.missingCards)
+		}
+	}
+}
 
-	//"https://www.17lands.com/card_ratings/data?expansion=%s&format=PremierDraft&start_date=%s&end_date%s&colors=%s"
-	var todayString = fmt.Sprintf("%d-%d-%d", time.Now().Year(), time.Now().Month(), time.Now().Day())
-	var uri string = fmt.Sprintf(seventeenLandsTemplate, setCode, setPerformanceFormat, todayString, deckId)
-	//var uri string = fmt.Sprintf(seventeenLandsTemplate, setCode, deckId)
-	rawJson, err := getWebResponseString(uri, seventeenLandsPauseMs)
+// Did we manage to get card data for the pool?  A genuinely empty pool counts as having data - there was just nothing to get.
+func (pool *PlayerPool) hasCardData() bool {
+	return len(pool.cards) > 0 || pool.missingCards == 0
+}
+
+// For a batch of pools, gather all the card data and dump it to a file.
+func (run *Run) processPools(db CardStore, pools []PlayerPool, poolType string, cardStrengthByDeck map[string]map[string]float64) {
+
+	// If the list of pools is empty, bail out
+	if len(pools) == 0 {
+		return
+	}
+
+	// Make a master list of all of the cards across the set of pools
+	allCards := flattenPools(pools)
+
+	// Write out a tab-delimited file for easy analysis
+	outputFileName := filepath.Join(run.config.OutputPath, fmt.Sprintf("ASL_%s_%s.txt", getFileTimestamp(), poolType))
+	outputFile, err := os.Create(outputFileName)
+	checkError(err)
+	writer := bufio.NewWriter(outputFile)
+
+	writer.WriteString("Name	Set	Rarity	ManaCost	TypeLine	PriceUSD	Amount")
+	if masterWinRates {
+		writer.WriteString("	GihWR")
+	}
+	writer.WriteString("\n")
+	for _, ds := range allCards {
+		theCard := ds.card
+		writer.WriteString(fmt.Sprintf("%s	%s	%s	%s	%s	%s	%d", theCard.Name, theCard.Set, theCard.Rarity, theCard.getManaCost(), theCard.getTypeLineClean(), theCard.Prices.Usd, ds.amount))
+		if masterWinRates {
+			// The card's best GIH WR across the archetypes, left blank if 17lands doesn't have it
+			writer.WriteString("	")
+			if wr := getBestWinRate(cardStrengthByDeck, theCard.Name); wr > 0 {
+				writer.WriteString(fmt.Sprintf("%.1f", wr*100))
+			}
+		}
+		writer.WriteString("\n")
+	}
+	writer.Flush()
+}
+
+// Place all cards into allCards.
+// Rules:
+// 1. If we haven't seen the card before, make a new entry for it
+// 2. If we have seen the card before, add the copies to the existing entry
+func (deck *SealedDeck) flatten() map[string]DeckSlot {
+	// Walk the deck & sideboard in turn (appending them would copy, and could scribble on the deck's backing array)
+	flattenedCards := make(map[string]DeckSlot, len(deck.Deck)+len(deck.Sideboard))
+	for sectionIndex, section := range [][]SealedDeckCard{deck.Deck, deck.Sideboard} {
+		for _, card := range section {
+			// A card with no copies is bad data from sealeddeck.tech - shout about it rather than silently counting nothing
+			if card.Count <= 0 {
+				fmt.Fprintf(progress, "WARNING: Skipping %s in pool %s, which has a count of %d\n", card.Name, deck.PoolID, card.Count)
+				continue
+			}
+
+			// A missing entry is a zero-copy slot, so this covers both new and existing cards
+			slot := flattenedCards[card.Name]
+			slot.cardName = card.Name
+			slot.amount += card.Count
+			if sectionIndex == 0 {
+				slot.deckAmount += card.Count
+			}
+			flattenedCards[card.Name] = slot
+		}
+	}
+
+	// Some pools come back with everything in the sideboard, because no deck was ever built.  The whole pool is the deck then,
+	// otherwise the maindeck-only numbers would come out empty.
+	if !deck.hasMainDeck() {
+		for name, slot := range flattenedCards {
+			slot.deckAmount = slot.amount
+			flattenedCards[name] = slot
+		}
+	}
+
+	return flattenedCards
+}
+
+// Was a main deck submitted for the pool?  Zero-copy entries (bad data) don't count.
+func (deck *SealedDeck) hasMainDeck() bool {
+	for _, card := range deck.Deck {
+		if card.Count > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Make a master list of all of the cards across a set of pools
+func flattenPools(pools []PlayerPool) map[string]DeckSlot {
+	// Most cards show up in more than one pool, so the biggest pool is a decent lower bound on the size
+	var sizeHint = 0
+	for _, pool := range pools {
+		if len(pool.cards) > sizeHint {
+			sizeHint = len(pool.cards)
+		}
+	}
+
+	allCards := make(map[string]DeckSlot, sizeHint)
+	for _, pool := range pools {
+		// Append the cards from the pool to the master list
+		flattenDeckSlots(allCards, pool.cards)
+	}
+	return allCards
+}
+
+// Place all cards into allCards.
+// Rules:
+// 1. If we haven't seen the card before, make a new entry for it
+// 2. If we have seen the card before, add the copies to the existing entry
+func flattenDeckSlots(allCards map[string]DeckSlot, cards []DeckSlot) {
+	// Add all cards from the main deck
+	for _, c := range cards {
+		if value, ok := allCards[c.cardName]; ok {
+			c.amount += value.amount
+			c.deckAmount += value.deckAmount
+		}
+		allCards[c.cardName] = c
+	}
+}
+
+// Get the call from the database, or if it's not already there, pull it from scryfall instead.
+// Note: the web scheduler keeps us a good citizen to scryfall, by spacing out the requests
+func (run *Run) getCard(db CardStore, cardName string, forceRefresh bool) (resultCard *ScryfallCard, err error) { // TODO: Add the card type to the return value
+
+	cardJson := ""
+	card := new(ScryfallCard)
+	cardName = getCardDbKey(cardName)
+
+	// A card that's already been looked up this run comes straight from memory
+	if !forceRefresh {
+		run.cardMemoMu.Lock()
+		memoCard, ok := run.cardMemo[cardName]
+		run.cardMemoMu.Unlock()
+		if ok {
+			return memoCard, nil
+		}
+	}
+
+	// Next try to get the card from the database
+	cardJson, err = dbGet(db, cardName)
+	if forceRefresh && !cachedOnly {
+		err = errKeyNotFound // treat it as uncached, so that it gets re-fetched and overwritten
+	}
+	if err != nil && cachedOnly {
+		return card, errors.New(fmt.Sprintf("Card is not in the db (and we're only using cached data): %s", cardName))
+	}
+	var stale = err == nil && isCachedCardStale(db, cardName)
+	if err != nil || stale {
+		// If the db lookup failed (or the card is past its TTL), try to get the card from scryfall.  Double-faced cards are found more reliably by their front face.
+		var fetchedJson string
+		fetchedJson, err = run.scryfallGet(getFrontFaceName(cardName))
+		if err != nil && getFrontFaceName(cardName) != cardName {
+			fetchedJson, err = run.scryfallGet(cardName)
+		}
+		if err != nil && stale {
+			fmt.Fprintln(progress, "Could not refresh card from scryfall, sticking with the cached one: ", cardName)
+		} else if err != nil {
+			return card, errors.New(fmt.Sprintf("Could not find card in db or in scryfall: %s", cardName))
+		} else {
+			// Store it in the database for next time
+			cardJson = fetchedJson
+			err = setCachedCard(db, cardName, cardJson)
+			if err != nil {
+				return card, errors.New(fmt.Sprintf("Could not cache card %s: %v", cardName, err))
+			}
+		}
+	}
+
+	// Remember it for the rest of the run, and return the card
+	json.Unmarshal([]byte(cardJson), &card)
+	run.cardMemoMu.Lock()
+	run.cardMemo[cardName] = card
+	run.cardMemoMu.Unlock()
+	return card, nil
+}
+
+// Has the card already been looked up this run?
+func (run *Run) isMemoizedCard(key string) bool {
+	run.cardMemoMu.Lock()
+	defer run.cardMemoMu.Unlock()
+	_, ok := run.cardMemo[key]
+	return ok
+}
+
+// Cache a card, along with when it was fetched (for -card-ttl)
+func setCachedCard(db CardStore, key, cardJson string) error {
+	err := dbSet(db, key, cardJson)
 	if err != nil {
-		fmt.Println("Error getting 17lands data: ", err)
+		return err
+	}
+	return markCardFetched(db, key)
+}
+
+// Note that the cached card was fetched just now
+func markCardFetched(db CardStore, key string) error {
+	return dbSet(db, key+"_fetched", nowFunc().Format(time.RFC3339))
+}
+
+// Is the cached card older than -card-ttl?  Cards cached before fetch times were kept count as fetched now, and get their fetch time
+// written the first time they're seen, so that a whole existing cache isn't re-fetched at once.
+func isCachedCardStale(db CardStore, key string) bool {
+	if cardTTL <= 0 || cachedOnly {
+		return false
+	}
+	fetchedAt, err := dbGet(db, key+"_fetched")
+	if err == errKeyNotFound {
+		markCardFetched(db, key) // if this doesn't stick, it'll be tried again next time
+		return false
+	}
+	return err != nil || isFetchTimeStale(fetchedAt)
+}
+
+// Does the card need to be fetched from scryfall, because it isn't cached or is past its TTL?  Usually that's a single read: the
+// fetch time when there's a -card-ttl (only cards cached before fetch times were kept need a second one), or else the card itself.
+func isCardFetchNeeded(db CardStore, key string) bool {
+	if cardTTL > 0 && !cachedOnly {
+		fetchedAt, err := dbGet(db, key+"_fetched")
+		if err == nil {
+			return isFetchTimeStale(fetchedAt)
+		}
+	}
+	if _, err := dbGet(db, key); err != nil {
+		return true
+	}
+	if cardTTL > 0 && !cachedOnly {
+		markCardFetched(db, key) // cached before fetch times were kept, so it counts as fetched now
+	}
+	return false
+}
+
+// Is a fetch time (as written by markCardFetched) older than -card-ttl?  One that can't be read counts as stale.
+func isFetchTimeStale(fetchedAt string) bool {
+	fetchedTime, err := time.Parse(time.RFC3339, fetchedAt)
+	return err != nil || nowFunc().Sub(fetchedTime) > cardTTL
+}
+
+// The key a card is cached under.  Remove the Alchemy designation from cards (always a capital A-) and then force all card names to lower case (for some sealeddeck oddities)
+func getCardDbKey(cardName string) string {
+	return strings.ToLower(strings.TrimPrefix(cardName, "A-"))
+}
+
+// Re-fetch the card data for every card in the pools, overwriting what's cached.  Handy after fixing a lookup bug, without wiping the whole cache.
+// With suspectOnly, only the cards that are missing, don't parse, or are cached under a different card's name get re-fetched.
+func (run *Run) backfillCards(db CardStore, pools []PlayerPool, suspectOnly bool) {
+	if cachedOnly {
+		fmt.Fprintln(progress, "Can't backfill card data with -cached-only")
+		return
+	}
+
+	// Every card across the pools, looked up once each
+	cardNames := make(map[string]bool)
+	for _, pool := range pools {
+		deck, err := getCardsFromPool(pool.player, pool.uri)
+		if err != nil {
+			fmt.Fprintf(progress, "Skipping %s's pool: %v\n", pool.player, err)
+			continue
+		}
+		for _, card := range deck.flatten() {
+			cardNames[card.cardName] = true
+		}
 	}
 
-	// And then wait for a few ms to be a good citizen
-	time.Sleep(seventeenLandsPauseMs * time.Millisecond)
+	var refetched, failed = 0, 0
+	for cardName := range cardNames {
+		if suspectOnly && !isSuspectCachedCard(db, cardName) {
+			continue
+		}
+		if _, err := run.getCard(db, cardName, true); err != nil {
+			fmt.Fprintln(progress, err)
+			failed += 1
+			continue
+		}
+		refetched += 1
+	}
+
+	fmt.Fprintf(progress, "Checked %d cards: re-fetched %d and failed to fetch %d\n", len(cardNames), refetched, failed)
+}
+
+// Does the cached data for a card look wrong?  Missing, unparseable, and cached under another card's name (e.g. a mangled Alchemy name) all count.
+func isSuspectCachedCard(db CardStore, cardName string) bool {
+	key := getCardDbKey(cardName)
+	cardJson, err := dbGet(db, key)
+	if err != nil || !isValidCardJson(cardJson) {
+		return true
+	}
+
+	card := new(ScryfallCard)
+	json.Unmarshal([]byte(cardJson), &card)
+	cachedName := strings.ToLower(card.Name)
+	return key != cachedName && key != getFrontFaceName(cachedName) && getFrontFaceName(key) != getFrontFaceName(cachedName)
+}
+
+// The front face of a double-faced (or split) card name, e.g. "delver of secrets // insectile aberration" -> "delver of secrets"
+func getFrontFaceName(cardName string) string {
+	if i := strings.Index(cardName, "//"); i > 0 {
+		if front := strings.TrimSpace(cardName[:i]); front != "" {
+			return front
+		}
+	}
+	return cardName
+}
+
+func (run *Run) scryfallGet(cardName string) (resultJson string, err error) {
+	fmt.Fprintln(progress, "Fetching card from Scryfall: ", cardName)
+
+	// We have a baseUri which fetches the card from whichever set scryfall fancies, and then a setUri that gets the card from the current set.
+	// We want to try the current set to get the specifics for a card, and if that fails, fallback to the base uri.
+	var baseUri string = fmt.Sprintf(scryfallCardTemplate, url.QueryEscape(cardName))
+	var setUri string = baseUri + fmt.Sprintf(scryfallSetClauseTemplate, url.QueryEscape(run.currentSet))
+
+	var rawJson string = ""
+	rawJson, err = getWebResponseString(setUri, scryfallPauseMs)
+	if err != nil {
+		fmt.Fprintf(progress, "%s isn't in %s on scryfall (%v), falling back to any set\n", cardName, run.currentSet, err)
+		rawJson, err = getWebResponseString(baseUri, scryfallPauseMs)
+		if err != nil {
+			fmt.Fprintln(progress, "Error fetching card from scryfall: ", err)
+		}
+	}
+
+	// If scryfall handed back a promo/boxtopper/etc. printing, go looking for one that was actually in draft boosters
+	if err == nil && !run.isKeptPrinting(rawJson) {
+		draftableJson, searchErr := run.scryfallSearchBestPrinting(cardName)
+		if searchErr == nil {
+			rawJson = draftableJson
+		}
+	}
+
+	return rawJson, err
+}
+
+// Look up a bunch of cards with scryfall's collection endpoint (scryfallCollectionMax at a time), and cache the ones it finds.
+// Cards are asked for from the current set, so anything from another set (or that scryfall can't match) comes back in notFound,
+// for the one-card-at-a-time lookup to deal with.  So does any printing that lookup wouldn't keep, so both paths cache the same printing.
+func (run *Run) scryfallGetBatch(db CardStore, cardNames []string) (notFound []string, err error) {
+	for start := 0; start < len(cardNames); start += scryfallCollectionMax {
+		end := start + scryfallCollectionMax
+		if end > len(cardNames) {
+			end = len(cardNames)
+		}
+		chunk := cardNames[start:end]
+		fmt.Fprintf(progress, "Fetching %d cards from Scryfall\n", len(chunk))
+
+		// Ask for each card by its front face, like the single lookup
+		identifiers := make([]map[string]string, 0, len(chunk))
+		for _, cardName := range chunk {
+			identifiers = append(identifiers, map[string]string{"name": getFrontFaceName(getCardDbKey(cardName)), "set": strings.ToLower(run.currentSet)})
+		}
+		requestJson, err := json.Marshal(map[string]interface{}{"identifiers": identifiers})
+		if err != nil {
+			return append(notFound, cardNames[start:]...), err
+		}
+
+		rawJson, err := postWebResponseString(scryfallCollectionUri, string(requestJson), scryfallPauseMs)
+		if err != nil {
+			return append(notFound, cardNames[start:]...), err
+		}
+		collection := new(ScryfallCollection)
+		err = json.Unmarshal([]byte(rawJson), &collection)
+		if err != nil {
+			return append(notFound, cardNames[start:]...), err
+		}
+
+		// Match the cards back up with the names we asked for, and cache them under those names
+		cardJsonByName := make(map[string]string)
+		for _, data := range collection.Data {
+			card := new(ScryfallCard)
+			if json.Unmarshal(data, &card) == nil && card.Name != "" {
+				cardJsonByName[getFrontFaceName(strings.ToLower(card.Name))] = string(data)
+			}
+		}
+		for i, cardName := range chunk {
+			key := getCardDbKey(cardName)
+			cardJson, ok := cardJsonByName[getFrontFaceName(key)]
+			if !ok || !run.isKeptPrinting(cardJson) {
+				notFound = append(notFound, cardName)
+				continue
+			}
+			if err := setCachedCard(db, key, cardJson); err != nil {
+				return append(notFound, cardNames[start+i:]...), err
+			}
+		}
+	}
+
+	return notFound, nil
+}
+
+// Search scryfall for every printing of a card, and pick the best one (see printingPreferences)
+func (run *Run) scryfallSearchBestPrinting(cardName string) (resultJson string, err error) {
+	fmt.Fprintln(progress, "Searching Scryfall for the best printing of: ", cardName)
+
+	var query = fmt.Sprintf("!\"%s\"", cardName)
+	var uri = fmt.Sprintf(scryfallSearchTemplate, url.QueryEscape(query))
+
+	rawJson, err := getWebResponseString(uri, scryfallPauseMs)
+	if err != nil {
+		return "", err
+	}
+
+	list := new(ScryfallList)
+	json.Unmarshal([]byte(rawJson), &list)
+	return run.selectBestPrinting(list.Data)
+}
+
+// Pick the best printing out of a list of them.
+//
+// Selection order:
+// 1. Each printing is scored against printingPreferences, in order.  The first preference that two printings disagree on decides between them.
+// 2. If the printings agree on every preference, the earlier one in the list wins (search results come back newest first).
+func (run *Run) selectBestPrinting(printings []json.RawMessage) (resultJson string, err error) {
+	if len(printings) == 0 {
+		return "", errors.New("No printings to choose from")
+	}
+
+	var best = 0
+	var bestCard = new(ScryfallCard)
+	json.Unmarshal(printings[0], &bestCard)
+	for i := 1; i < len(printings); i++ {
+		card := new(ScryfallCard)
+		json.Unmarshal(printings[i], &card)
+		if run.isPreferredPrinting(card, bestCard) {
+			best = i
+			bestCard = card
+		}
+	}
+
+	return string(printings[best]), nil
+}
+
+// Is printing a strictly preferred over printing b?
+func (run *Run) isPreferredPrinting(a *ScryfallCard, b *ScryfallCard) bool {
+	for _, pref := range printingPreferences {
+		prefersA, prefersB := pref.prefers(run, a), pref.prefers(run, b)
+		if prefersA != prefersB {
+			return prefersA
+		}
+	}
+	return false
+}
+
+// Shrink the database, which otherwise grows from season to season.  The current set's 17lands data is cached under the day it was fetched,
+// so every day's copy but today's is dead weight (a set that's no longer current is looked up under its undated key instead).
+// Once those are gone, the store gets compacted (badger's value log is garbage collected, SQLite is vacuumed) to hand the space back.
+func rebuildCache(db CardStore, storePath string) {
+	fmt.Fprintln(progress, "Rebuilding the database....")
+	sizeBefore := getDirSize(storePath)
+
+	entries, err := dbGetAll(db)
+	checkError(err)
+	today := nowFunc().Format("2006_01_02")
+	var deleted = 0
+	for key := range entries {
+		if !strings.HasPrefix(key, "17lands_") {
+			continue
+		}
+		match := perfDbKeyDatePattern.FindStringSubmatch(key)
+		if match == nil {
+			continue
+		}
+		year, _ := strconv.Atoi(match[1])
+		month, _ := strconv.Atoi(match[2])
+		day, _ := strconv.Atoi(match[3])
+		if fmt.Sprintf("%04d_%02d_%02d", year, month, day) == today {
+			continue
+		}
+		checkError(dbDelete(db, key))
+		deleted += 1
+	}
+
+	checkError(db.Compact())
+
+	sizeAfter := getDirSize(storePath)
+	fmt.Fprintf(progress, "Deleted %d of %d keys, and reclaimed %.1f MB\n", deleted, len(entries), float64(sizeBefore-sizeAfter)/(1024*1024))
+}
+
+// Total size of the files under a directory, or 0 if it can't be read
+func getDirSize(path string) int64 {
+	var size int64
+	filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
+
+// Walk the whole database looking for entries that don't parse as what they should be (e.g. an error page cached during an outage).
+// Bad cards are re-fetched from scryfall; bad perf data is deleted so that the next run grabs it again.
+func (run *Run) repairDatabase(db CardStore) {
+	fmt.Fprintln(progress, "Checking the database for bad entries....")
+	entries, err := dbGetAll(db)
+	checkError(err)
+
+	var refetched, deleted = 0, 0
+	for key, value := range entries {
+		switch {
+		case strings.HasSuffix(key, "_fetched"):
+			continue // timestamps
+		case strings.HasPrefix(key, "sheet_"), strings.HasPrefix(key, "watch_"):
+			continue // cached copies of the sheet, and watched card histories
+		case strings.HasPrefix(key, "17lands_"):
+			if isValidCardPerformanceJson(value) {
+				continue
+			}
+			fmt.Fprintln(progress, "Deleting bad perf data: ", key)
+			checkError(dbDelete(db, key))
+			deleted += 1
+		default:
+			if isValidCardJson(value) {
+				continue
+			}
+			fmt.Fprintln(progress, "Re-fetching bad card: ", key)
+			cardJson, err := run.scryfallGet(key)
+			if err == nil && isValidCardJson(cardJson) {
+				checkError(setCachedCard(db, key, cardJson))
+				refetched += 1
+			} else {
+				checkError(dbDelete(db, key))
+				deleted += 1
+			}
+		}
+	}
+
+	fmt.Fprintf(progress, "Checked %d entries: re-fetched %d and deleted %d\n", len(entries), refetched, deleted)
+}
+
+// Scryfall's prices change daily, so once they get stale pull fresh ones for all the cached cards.  A typical cache is asked for through
+// the collection endpoint, while a big one comes out of the bulk data instead (one big download instead of a lot of requests).
+// Everything else about the cached cards is left alone.
+func refreshCardPrices(db CardStore) {
+	if priceRefreshInterval <= 0 || cachedOnly {
+		return
+	}
+	lastRefresh, err := dbGet(db, scryfallPricesFetchedDbKey)
+	if err == nil {
+		refreshedAt, err := time.Parse(time.RFC3339, lastRefresh)
+		if err == nil && nowFunc().Sub(refreshedAt) < priceRefreshInterval {
+			return
+		}
+	}
+
+	// Find the printing behind each cached card (a printing can be cached under a couple of names)
+	entries, err := dbGetAll(db)
+	checkError(err)
+	keysById := make(map[string][]string)
+	for key, value := range entries {
+		if strings.HasSuffix(key, "_fetched") || strings.HasPrefix(key, "17lands_") {
+			continue
+		}
+		card := new(ScryfallCard)
+		if json.Unmarshal([]byte(value), &card) == nil && card.ID != "" {
+			keysById[card.ID] = append(keysById[card.ID], key)
+		}
+	}
+	if len(keysById) == 0 {
+		return
+	}
+
+	var pricesById map[string]json.RawMessage
+	if len(keysById) > scryfallBulkPricesThreshold {
+		fmt.Fprintln(progress, "Refreshing card prices from scryfall's bulk data....")
+		pricesById, err = getBulkPrices(keysById)
+	} else {
+		fmt.Fprintf(progress, "Refreshing the prices of %d printings from scryfall....\n", len(keysById))
+		pricesById, err = getCollectionPrices(keysById)
+	}
+	if err != nil {
+		fmt.Fprintln(progress, "Could not refresh card prices, sticking with the cached ones: ", err)
+		return
+	}
+
+	var updated = 0
+	for id, prices := range pricesById {
+		for _, key := range keysById[id] {
+			cardJson, err := setCardPrices(entries[key], prices)
+			if err != nil {
+				continue
+			}
+			checkError(dbSet(db, key, cardJson))
+			updated += 1
+		}
+	}
+	checkError(dbSet(db, scryfallPricesFetchedDbKey, nowFunc().Format(time.RFC3339)))
+	fmt.Fprintf(progress, "Refreshed the prices of %d cards\n", updated)
+}
+
+// Stream through scryfall's bulk data (it's big) and pick out the prices of the printings that we want
+func getBulkPrices(wantedIds map[string][]string) (map[string]json.RawMessage, error) {
+	bulkJson, err := getWebResponseString(scryfallBulkDataUri, scryfallPauseMs)
+	if err != nil {
+		return nil, err
+	}
+	var bulkData ScryfallBulkData
+	err = json.Unmarshal([]byte(bulkJson), &bulkData)
+	if err != nil || bulkData.DownloadURI == "" {
+		return nil, errors.New(fmt.Sprintf("Scryfall's bulk data didn't say where to download it: %s", bulkJson))
+	}
+
+	var statusCode = 0
+	release := webScheduler.acquire(bulkData.DownloadURI)
+	defer func() { release(statusCode) }()
+
+	req, err := newWebRequest("GET", bulkData.DownloadURI, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := downloadHttpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	statusCode = resp.StatusCode
+	if resp.StatusCode != 200 {
+		return nil, &WebError{statusCode: resp.StatusCode, uri: bulkData.DownloadURI}
+	}
+
+	pricesById := make(map[string]json.RawMessage)
+	decoder := json.NewDecoder(resp.Body)
+	if _, err := decoder.Token(); err != nil { // the opening [
+		return nil, err
+	}
+	for decoder.More() {
+		var card ScryfallBulkCard
+		if err := decoder.Decode(&card); err != nil {
+			return nil, err
+		}
+		if _, ok := wantedIds[card.ID]; ok {
+			pricesById[card.ID] = card.Prices
+		}
+	}
+	return pricesById, nil
+}
+
+// Ask scryfall's collection endpoint for the printings we want by id (scryfallCollectionMax at a time), and pick out their prices
+func getCollectionPrices(wantedIds map[string][]string) (map[string]json.RawMessage, error) {
+	ids := make([]string, 0, len(wantedIds))
+	for id := range wantedIds {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	pricesById := make(map[string]json.RawMessage)
+	for start := 0; start < len(ids); start += scryfallCollectionMax {
+		end := start + scryfallCollectionMax
+		if end > len(ids) {
+			end = len(ids)
+		}
+		identifiers := make([]map[string]string, 0, end-start)
+		for _, id := range ids[start:end] {
+			identifiers = append(identifiers, map[string]string{"id": id})
+		}
+		requestJson, err := json.Marshal(map[string]interface{}{"identifiers": identifiers})
+		if err != nil {
+			return nil, err
+		}
+
+		rawJson, err := postWebResponseString(scryfallCollectionUri, string(requestJson), scryfallPauseMs)
+		if err != nil {
+			return nil, err
+		}
+		collection := new(ScryfallCollection)
+		if err := json.Unmarshal([]byte(rawJson), &collection); err != nil {
+			return nil, err
+		}
+		for _, data := range collection.Data {
+			var card ScryfallBulkCard
+			if json.Unmarshal(data, &card) == nil && card.ID != "" {
+				pricesById[card.ID] = card.Prices
+			}
+		}
+	}
+	return pricesById, nil
+}
+
+// Swap the prices in a card's json for new ones, leaving the rest of it as scryfall sent it
+func setCardPrices(cardJson string, prices json.RawMessage) (string, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(cardJson), &fields); err != nil {
+		return "", err
+	}
+	fields["prices"] = prices
+	updated, err := json.Marshal(fields)
+	return string(updated), err
+}
+
+// Does the json look like a scryfall card?
+func isValidCardJson(cardJson string) bool {
+	card := new(ScryfallCard)
+	err := json.Unmarshal([]byte(cardJson), &card)
+	return err == nil && card.Object == "card" && card.Name != ""
+}
+
+// Does the json look like 17lands card performance data?
+func isValidCardPerformanceJson(perfJson string) bool {
+	cp := new(CardPerformance)
+	return json.Unmarshal([]byte(perfJson), &cp) == nil
+}
+
+// The set that most of the pools' cards are from, which is almost certainly the one being drafted.  Cards from set types
+// that aren't in draft boosters (promos, The List, etc.) or from an ignored set code don't get a vote.
+func getMostCommonSet(pools []PlayerPool) string {
+	votes := make(map[string]int)
+	for _, p := range pools {
+		for _, ds := range p.cards {
+			if ds.isIgnored() || !isDraftableSetType(ds.card.SetType) || containsString(detectSetIgnoredCodes, strings.ToUpper(ds.card.Set)) {
+				continue
+			}
+			votes[strings.ToUpper(ds.card.Set)] += ds.amount
+		}
+	}
+
+	var mostCommonSet, mostVotes = "", 0
+	for setCode, count := range votes {
+		if count > mostVotes || (count == mostVotes && setCode < mostCommonSet) {
+			mostCommonSet, mostVotes = setCode, count
+		}
+	}
+	return mostCommonSet
+}
+
+// Is the printing described by the json from a set type that shows up in draft boosters?
+func isDraftablePrinting(cardJson string) bool {
+	card := new(ScryfallCard)
+	json.Unmarshal([]byte(cardJson), &card)
+	return isDraftableSetType(card.SetType)
+}
+
+// Can a printing that scryfall handed back be cached as is, or should the best printing be searched for?  The current set's own printing
+// is always kept, whatever its set type (alchemy and masters sets aren't on the draftable list), and so is any draftable printing.
+func (run *Run) isKeptPrinting(cardJson string) bool {
+	return run.isCurrentSetPrinting(cardJson) || isDraftablePrinting(cardJson)
+}
+
+// Is the printing described by the json from the current set?
+func (run *Run) isCurrentSetPrinting(cardJson string) bool {
+	card := new(ScryfallCard)
+	json.Unmarshal([]byte(cardJson), &card)
+	return strings.EqualFold(card.Set, run.currentSet)
+}
+
+func isDraftableSetType(setType string) bool {
+	for _, st := range draftableSetTypes {
+		if st == setType {
+			return true
+		}
+	}
+	return false
+}
+
+// Load all deck card performance data for all decks
+func (run *Run) loadCardPerformanceData(db CardStore) map[string]map[string]float64 {
+
+	var cpByDeck = make(map[string]map[string]float64)
+
+	// Walk the sets in order, and process the ones that we detect cards for
+	for _, setCode := range allSeventeenLandsSets {
+		if run.setsInPools[setCode] == 1 {
+			fmt.Fprintln(progress, "Fetching card performance data for ", setCode)
+
+			// Grab 17lands perf data for this set
+			// Note: If a specific card is in multiple sets, we grab the latest
+			for _, deckId := range run.getDecks(setCode) {
+				// Blend the GIH_WR across the formats (usually just the one), weighting each format that has data for the card
+				var weightedGihByCard = make(map[string]float64)
+				var weightByCard = make(map[string]float64)
+				var foundData = false
+				for _, fw := range getPerformanceFormats() {
+					cp, err := run.getCardPerformanceData(db, setCode, fw.format, deckId, false)
+
+					// Shoot - we couldn't get perf data for this card.  Skip it for now?
+					if err != nil {
+						continue
+					}
+					foundData = true
+
+					// Keep track of how fresh and deep the current set's data is, so the report can carry a caveat
+					if setCode == run.currentSet {
+						run.perfFreshness.record(run, db, setCode, fw.format, deckId, cp)
+					}
+
+					// Extract the GIH_WR (and the pick position while we're here)
+					for _, cardData := range cp {
+						if cardData.PickCount > run.cardPicks[cardData.Name].pickCount {
+							run.cardPicks[cardData.Name] = CardPick{avgPick: cardData.AvgPick, pickCount: cardData.PickCount}
+						}
+						run.cardRarities[cardData.Name] = cardData.Rarity
+
+						if _, ok := weightedGihByCard[cardData.Name]; !ok {
+							weightedGihByCard[cardData.Name] = 0
+						}
+						if cardData.EverDrawnGameCount > getCardPrevalenceThreshold(cardData.Rarity) { // filter out rarely played cards
+							weightedGihByCard[cardData.Name] += fw.weight * cardData.EverDrawnWinRate
+							weightByCard[cardData.Name] += fw.weight
+						}
+					}
+				}
+				if !foundData {
+					continue
+				}
+
+				var gihByCard = make(map[string]float64)
+				for cardName, weightedGih := range weightedGihByCard {
+					gihByCard[cardName] = 0
+					if weightByCard[cardName] > 0 {
+						gihByCard[cardName] = weightedGih / weightByCard[cardName]
+					}
+				}
+
+				cpByDeck[deckId] = gihByCard
+			} // end for
+		} // end if
+	} // end for
+
+	return cpByDeck
+}
+
+// Load a csv of CardName,Rating as a stand-in for 17lands data.  Ratings don't know about archetypes, so every deck gets the same map.
+// Ratings are scaled to 0-1 so that they line up with win rates.
+func (run *Run) loadCardRatings(fileName string) (map[string]map[string]float64, error) {
+	fmt.Fprintln(progress, "Reading card ratings from file: ", fileName)
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	ratings := make(map[string]float64)
+	for i, row := range rows {
+		if len(row) < 2 {
+			return nil, errors.New(fmt.Sprintf("Line %d of %s needs two columns: CardName,Rating", i+1, fileName))
+		}
+		rating, ok := parseRating(row[1])
+		if !ok {
+			if i == 0 {
+				continue // header
+			}
+			return nil, errors.New(fmt.Sprintf("Line %d of %s has a bad rating: %s", i+1, fileName, row[1]))
+		}
+		ratings[strings.TrimSpace(row[0])] = rating / 100.0
+	}
+
+	cardStrengthByDeck := make(map[string]map[string]float64)
+	for _, deckId := range run.getDecks(run.currentSet) {
+		cardStrengthByDeck[deckId] = ratings
+	}
+	return cardStrengthByDeck, nil
+}
+
+// A 0-100 rating from either a number or a letter grade
+func parseRating(value string) (float64, bool) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	if rating, ok := letterGradeRatings[value]; ok {
+		return rating, true
+	}
+	rating, err := strconv.ParseFloat(value, 64)
+	if err != nil || rating < 0 || rating > 100 {
+		return 0, false
+	}
+	return rating, true
+}
+
+// How the cards perform, by deck (or how someone rates them, if we were given ratings)
+func (run *Run) loadCardStrengths(db CardStore) map[string]map[string]float64 {
+	if ratingsFile != "" {
+		cardStrengthByDeck, err := run.loadCardRatings(ratingsFile)
+		checkError(err)
+		return cardStrengthByDeck
+	}
+	return run.loadCardPerformanceData(db) // TODO: all the sets that we care about....
+}
+
+// The 17lands formats (and their weights) that feed into strength
+func getPerformanceFormats() []FormatWeight {
+	if len(setPerformanceBlend) > 0 {
+		return setPerformanceBlend
+	}
+	return []FormatWeight{{setPerformanceFormat, 1}}
+}
+
+// Parse a list of formats and weights, like "PremierDraft:0.7,TradDraft:0.3"
+func parseFormatWeights(value string) ([]FormatWeight, error) {
+	weights := make([]FormatWeight, 0)
+	for _, part := range strings.Split(value, ",") {
+		pieces := strings.Split(strings.TrimSpace(part), ":")
+		if len(pieces) != 2 {
+			return nil, errors.New(fmt.Sprintf("Expected format:weight, got: %s", part))
+		}
+		weight, err := strconv.ParseFloat(pieces[1], 64)
+		if err != nil || weight < 0 {
+			return nil, errors.New(fmt.Sprintf("Bad weight for %s: %s", pieces[0], pieces[1]))
+		}
+		weights = append(weights, FormatWeight{pieces[0], weight})
+	}
+	return weights, nil
+}
+
+// Get the call from the database, or if it's not already there, pull it from 17lands.com instead.
+func (run *Run) getCardPerformanceData(db CardStore, setCode string, format string, deckId string, forceDataRefresh bool) (resultCard CardPerformance, err error) {
+	rawJson := ""
+	cp := new(CardPerformance)
+
+	var dbKey = run.getCardPerformanceDbKey(setCode, format, deckId)
+
+	// Try to get the card from the database
+	rawJson, err = dbGet(db, dbKey)
+	if err != nil || strings.TrimSpace(rawJson) == "" || forceDataRefresh {
+		// If the db lookup failed, try to get the data from 17lands
+		rawJson, err = seventeenLandsGet(setCode, format, deckId)
+		if err != nil {
+			return *cp, errors.New(fmt.Sprintf("Could not find card perf data in db or on 17lands.com: %s", deckId))
+		}
+
+		// Store it in the database for next time, noting when we grabbed it
+		err = dbSet(db, dbKey, rawJson)
+		if err == nil {
+			err = dbSet(db, dbKey+"_fetched", nowFunc().Format(time.RFC3339))
+		}
+		if err != nil {
+			return *cp, errors.New(fmt.Sprintf("Could not cache card perf data for %s: %v", deckId, err))
+		}
+	}
+
+	// Return the card
+	json.Unmarshal([]byte(rawJson), &cp)
+	return *cp, nil
+}
+
+// Build the key to access the set perf data.  If the set is the current one we'll refresh daily.  Otherwise, we rely on cached data
+// The default format is left out of the key so that data cached before formats were configurable is still found.
+func (run *Run) getCardPerformanceDbKey(setCode string, format string, deckId string) string {
+	var dateKey = ""
+	if setCode == run.currentSet {
+		dateKey = "_" + nowFunc().Format("2006_01_02")
+	}
+	var formatKey = ""
+	if format != defaultPerformanceFormat {
+		formatKey = "_" + format
+	}
+	return fmt.Sprintf("17lands_%s_%s%s%s", setCode, deckId, formatKey, dateKey)
+}
+
+// Note the sample size and fetch date of a deck's perf data.
+// 17lands doesn't hand back a total game count, so the most games any one card was in is used as the sample size for the deck.
+func (freshness *PerfDataFreshness) record(run *Run, db CardStore, setCode string, format string, deckId string, cp CardPerformance) {
+	var games = 0
+	for _, cardData := range cp {
+		if cardData.GameCount > games {
+			games = cardData.GameCount
+		}
+	}
+
+	freshness.Set = setCode
+	if !containsString(freshness.Formats, format) {
+		freshness.Formats = append(freshness.Formats, format)
+	}
+	freshness.GamesByDeck[deckId] += games
+	freshness.TotalGames += games
+
+	// Report the oldest fetch across the decks
+	fetchedAt, err := dbGet(db, run.getCardPerformanceDbKey(setCode, format, deckId)+"_fetched")
+	if err == nil && (freshness.FetchedAt == "" || fetchedAt < freshness.FetchedAt) {
+		freshness.FetchedAt = fetchedAt
+	}
+}
+
+func seventeenLandsGet(setCode string, format string, deckId string) (resultJson string, err error) {
+	fmt.Fprintln(progress, "Fetching card performance data from 17lands.com: ", deckId)
+
+	//"https://www.17lands.com/card_ratings/data?expansion=%s&format=PremierDraft&start_date=%s&end_date%s&colors=%s"
+	var todayString = nowFunc().Format("2006-01-02")
+	var uri string = fmt.Sprintf(seventeenLandsTemplate, setCode, format, todayString, deckId)
+	//var uri string = fmt.Sprintf(seventeenLandsTemplate, setCode, deckId)
+	rawJson, err := getWebResponseString(uri, seventeenLandsPauseMs)
+	if err != nil {
+		fmt.Fprintln(progress, "Error getting 17lands data: ", err)
+	}
+
+	return rawJson, err
+}
+
+// A dumb little function that looks for a bunch of neato stats
+func (run *Run) processFunFacts(db CardStore, pools []PlayerPool, cardStrengthByDeck map[string]map[string]float64) {
+
+	// Strength can depend on what everyone else is drafting, so size up the field first
+	if contestedColourWeight > 0 {
+		run.fieldColourShares = getFieldColourShares(pools)
+	}
+
+	// We're going to zip through all of the pools, and add facts about each to them
+	for i := range pools {
+		pools[i].addFacts(run, cardStrengthByDeck)
+		pools[i].addLegalityFacts(legalityFormat)
+		if explainPlayer != "" && strings.EqualFold(pools[i].player, explainPlayer) {
+			pools[i].explainStrength()
+		}
+	}
+
+	// Now that every pool has a strength, see who is over/under-performing their pool
+	addLuckFacts(pools)
+	addQualityFacts(pools)
+	outputBaseName := filepath.Join(run.config.OutputPath, fmt.Sprintf("ASL_%s_funfacts", getFileTimestamp()))
+
+	// Optionally keep the dead pools off of the leaderboard (or give them their own file)
+	reportPools := pools
+	if deadPoolsOutput != "include" {
+		reportPools = make([]PlayerPool, 0, len(pools))
+		deadPools := make([]PlayerPool, 0)
+		for _, p := range pools {
+			if p.isAlive {
+				reportPools = append(reportPools, p)
+			} else {
+				deadPools = append(deadPools, p)
+			}
+		}
+		if deadPoolsOutput == "separate" {
+			for _, format := range outputFormats {
+				writer, closeOutput := openOutput("", outputBaseName+"_dead."+format)
+				run.writeFunFacts(writer, deadPools, format)
+				closeOutput()
+			}
+		}
+	}
+
+	// Write out all of the facts, once per format
+	for _, format := range outputFormats {
+		writer, closeOutput := openOutput(getFormatOutputName(funFactsOutput, format), outputBaseName+"."+format)
+		run.writeFunFacts(writer, reportPools, format)
+		closeOutput()
+	}
+
+	// See what changed since the last run
+	if compareLatest {
+		run.compareWithLatestFunFacts(outputBaseName+".csv", reportPools)
+	}
+
+	// Drop the 17lands freshness next to the facts, since early-set strength numbers are noisy
+	metaJson, err := json.MarshalIndent(run.perfFreshness, "", "  ")
+	checkError(err)
+	err = ioutil.WriteFile(outputBaseName+"_meta.json", metaJson, 0644)
+	checkError(err)
+
+	// And one file per pool, for anyone building per-player pages
+	if perPoolOutput {
+		writePerPoolFiles(pools, outputBaseName+"_pools")
+	}
+
+	// Players live in the league sheet, so put a copy of the facts there too
+	if statsSheetName != "" && !offlineSheet {
+		err = run.writeFunFactsToSheet(run.config.LeagueSheetID, statsSheetName, run.config.GoogleApiSecretFile, reportPools)
+		if err != nil {
+			fmt.Fprintln(progress, "Could not copy the fun facts to the sheet: ", err)
+		}
+	}
+}
+
+// Print the strength & record changes since the most recent earlier fun facts csv in the output folder
+func (run *Run) compareWithLatestFunFacts(currentFileName string, pools []PlayerPool) {
+	fileNames, err := filepath.Glob(filepath.Join(run.config.OutputPath, "ASL_*_funfacts.csv"))
+	checkError(err)
+
+	// Older names didn't sort by date, so go by when the files were written
+	var latestFileName = ""
+	var latestModTime time.Time
+	for _, fileName := range fileNames {
+		info, err := os.Stat(fileName)
+		if err != nil || fileName == currentFileName {
+			continue
+		}
+		if info.ModTime().After(latestModTime) {
+			latestFileName, latestModTime = fileName, info.ModTime()
+		}
+	}
+	if latestFileName == "" {
+		fmt.Fprintln(progress, "No earlier fun facts to compare against")
+		return
+	}
+
+	file, err := os.Open(latestFileName)
+	checkError(err)
+	defer file.Close()
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil || len(rows) == 0 {
+		fmt.Fprintln(progress, "Could not read the earlier fun facts: ", latestFileName)
+		return
+	}
+
+	// Older files can have different columns, so find the ones we want by name
+	columns := make(map[string]int)
+	for i, name := range rows[0] {
+		columns[name] = i
+	}
+	playerColumn, hasPlayer := columns["Player"]
+	strengthColumn, hasStrength := columns["Strength"]
+	recordColumn, hasRecord := columns["Record"]
+	if !hasPlayer || !hasStrength || !hasRecord {
+		fmt.Fprintln(progress, "The earlier fun facts don't have Player, Strength and Record columns: ", latestFileName)
+		return
+	}
+	previous := make(map[string][]string)
+	for _, row := range rows[1:] {
+		if len(row) > playerColumn && len(row) > strengthColumn && len(row) > recordColumn {
+			previous[row[playerColumn]] = row
+		}
+	}
+
+	fmt.Fprintln(progress, "\nChanges since ", latestFileName)
+	var changes = 0
+	for _, p := range pools {
+		row, ok := previous[p.player]
+		if !ok {
+			fmt.Fprintf(progress, "  %s: new\n", p.player)
+			changes += 1
+			continue
+		}
+		delete(previous, p.player)
+
+		strength := fmt.Sprintf("%.1f", p.stats.Strength)
+		previousStrength, _ := strconv.ParseFloat(row[strengthColumn], 64)
+		if math.Abs(previousStrength-p.stats.Strength) >= 0.05 || row[recordColumn] != p.record {
+			fmt.Fprintf(progress, "  %s: strength %s -> %s, record %s -> %s\n", p.player, row[strengthColumn], strength, row[recordColumn], p.record)
+			changes += 1
+		}
+	}
+	for player := range previous {
+		fmt.Fprintf(progress, "  %s: gone\n", player)
+		changes += 1
+	}
+	if changes == 0 {
+		fmt.Fprintln(progress, "  Nothing")
+	}
+}
+
+// Write the facts in whichever format was asked for
+func (run *Run) writeFunFacts(writer *bufio.Writer, pools []PlayerPool, format string) {
+	switch format {
+	case "json":
+		writeFunFactsJson(writer, pools)
+	case "jsonl":
+		writeFunFactsJsonLines(writer, pools)
+	default:
+		run.writeFunFactsCsv(writer, pools)
+	}
+}
+
+// The zero-padded date & time that output file names are stamped with, e.g. 2024_03_05_09_07
+func getFileTimestamp() string {
+	return nowFunc().Format("2006_01_02_15_04")
+}
+
+// When several formats are asked for, a named output file gets the format's extension (facts.csv -> facts.csv & facts.json), so they don't overwrite each other
+func getFormatOutputName(fileName string, format string) string {
+	if fileName == "" || fileName == "-" || len(outputFormats) < 2 {
+		return fileName
+	}
+	return strings.TrimSuffix(fileName, filepath.Ext(fileName)) + "." + format
+}
+
+// Open somewhere to write a report: stdout for "-", the named file, or the default file if no name was given.
+// Call the returned func once the report is written.
+func openOutput(fileName string, defaultFileName string) (*bufio.Writer, func()) {
+	if fileName == "-" {
+		writer := bufio.NewWriter(os.Stdout)
+		return writer, func() { writer.Flush() }
+	}
+
+	if fileName == "" {
+		fileName = defaultFileName
+	}
+	outputFile, err := os.Create(fileName)
+	checkError(err)
+	writer := bufio.NewWriter(outputFile)
+	return writer, func() {
+		writer.Flush()
+		outputFile.Close()
+	}
+}
+
+// Write the facts as a json list of pools (without their card lists)
+func writeFunFactsJson(writer *bufio.Writer, pools []PlayerPool) {
+	reports := make([]PoolReport, 0, len(pools))
+	for _, p := range pools {
+		report := p.toReport()
+		report.Cards = nil
+		reports = append(reports, report)
+	}
+
+	factsJson, err := json.MarshalIndent(reports, "", "  ")
+	checkError(err)
+	writer.Write(factsJson)
+	writer.WriteString("\n")
+}
+
+// Write the facts as one json object per line, flushing as each pool goes out so that a pipe can start on them right away.
+// Unlike writeFunFactsJson, the reports are never all held at once.
+func writeFunFactsJsonLines(writer *bufio.Writer, pools []PlayerPool) {
+	encoder := json.NewEncoder(writer)
+	for _, p := range pools {
+		report := p.toReport()
+		report.Cards = nil
+		checkError(encoder.Encode(report))
+		writer.Flush()
+	}
+}
+
+// Write the facts as a csv, one row per pool
+func (run *Run) writeFunFactsCsv(writer *bufio.Writer, pools []PlayerPool) {
+	headers := make([]string, 0, len(funFactColumns))
+	for _, column := range funFactColumns {
+		headers = append(headers, column.name)
+	}
+	// Plus each archetype's strength, so that analysts can see more than the blended top 3
+	deckIds := run.getDecks(run.currentSet)
+	headers = append(headers, deckIds...)
+	writer.WriteString(strings.Join(headers, ",") + "\n")
+
+	for i := range pools {
+		values := make([]string, 0, len(headers))
+		for _, column := range funFactColumns {
+			values = append(values, column.value(&pools[i]))
+		}
+		for _, deckId := range deckIds {
+			values = append(values, fmt.Sprintf("%d", int(pools[i].deckStrengths[deckId]*100)))
+		}
+		writer.WriteString(strings.Join(values, ",") + "\n")
+	}
+}
+
+// Replace the contents of a tab in the sheet (adding the tab if it isn't there yet) with the fun facts table
+func (run *Run) writeFunFactsToSheet(sheetID, targetRange, secretFileName string, pools []PlayerPool) error {
+	tabName, cellRange := targetRange, ""
+	if i := strings.LastIndex(targetRange, "!"); i >= 0 {
+		tabName, cellRange = strings.Trim(targetRange[:i], "'"), targetRange[i+1:]
+	}
+
+	srv, err := run.getSheetsService(secretFileName)
+	if err != nil {
+		return err
+	}
+
+	// Reuse the csv so that the sheet always has the same columns as the file
+	var csvBuffer bytes.Buffer
+	writer := bufio.NewWriter(&csvBuffer)
+	run.writeFunFactsCsv(writer, pools)
+	writer.Flush()
+	rows, err := csv.NewReader(&csvBuffer).ReadAll()
+	if err != nil {
+		return err
+	}
+	values := make([][]interface{}, len(rows))
+	for i, row := range rows {
+		values[i] = make([]interface{}, len(row))
+		for j, cell := range row {
+			values[i][j] = cell
+		}
+	}
+
+	// Make the tab if this is the first time through
+	spreadsheet, err := srv.Spreadsheets.Get(sheetID).Do()
+	if err != nil {
+		return run.sheetsAuthError(err)
+	}
+	var tabExists = false
+	for _, s := range spreadsheet.Sheets {
+		if s.Properties != nil && s.Properties.Title == tabName {
+			tabExists = true
+		}
+	}
+	if !tabExists {
+		fmt.Fprintln(progress, "Adding a tab to the sheet: ", tabName)
+		addTab := &sheets.BatchUpdateSpreadsheetRequest{Requests: []*sheets.Request{{AddSheet: &sheets.AddSheetRequest{Properties: &sheets.SheetProperties{Title: tabName}}}}}
+		_, err = srv.Spreadsheets.BatchUpdate(sheetID, addTab).Do()
+		if err != nil {
+			return err
+		}
+	}
+
+	// Clear out the last run (it could have had more players or columns) and write this one.  With a range, only the range gets touched.
+	tabRange := fmt.Sprintf("'%s'", tabName)
+	writeRange := tabRange + "!A1"
+	if cellRange != "" {
+		tabRange += "!" + cellRange
+		writeRange = tabRange
+	}
+	_, err = srv.Spreadsheets.Values.Clear(sheetID, tabRange, &sheets.ClearValuesRequest{}).Do()
+	if err != nil {
+		return err
+	}
+	_, err = srv.Spreadsheets.Values.Update(sheetID, writeRange, &sheets.ValueRange{Values: values}).ValueInputOption("USER_ENTERED").Do()
+	return err
+}
+
+// Write each pool to its own json file (named for the player) in the given directory
+func writePerPoolFiles(pools []PlayerPool, outputDir string) {
+	err := os.MkdirAll(outputDir, 0755)
+	checkError(err)
+
+	usedNames := make(map[string]int)
+	for _, p := range pools {
+		// Two players can sanitize down to the same name, so number the repeats
+		fileName := sanitizeFileName(p.player)
+		usedNames[fileName] += 1
+		if usedNames[fileName] > 1 {
+			fileName = fmt.Sprintf("%s_%d", fileName, usedNames[fileName])
+		}
+
+		poolJson, err := json.MarshalIndent(p.toReport(), "", "  ")
+		checkError(err)
+		err = ioutil.WriteFile(filepath.Join(outputDir, fileName+".json"), poolJson, 0644)
+		checkError(err)
+	}
+}
+
+// Flatten a pool into something that can be written out as json
+func (pool *PlayerPool) toReport() PoolReport {
+	report := PoolReport{Player: pool.player, Team: pool.team, IsAlive: pool.isAlive, Record: pool.record, Stats: pool.stats, DeckStrengths: pool.deckStrengths}
+	for _, ds := range pool.cards {
+		report.Cards = append(report.Cards, PoolReportCard{Name: ds.cardName, Amount: ds.amount, Set: ds.card.Set, Rarity: ds.card.Rarity})
+	}
+	return report
+}
+
+// For pools with a history, list what changed since the previous pool (i.e. what the latest add-pack brought in)
+func (run *Run) processAddPacks(pools []PlayerPool) {
+	outputFileName := filepath.Join(run.config.OutputPath, fmt.Sprintf("ASL_%s_addpacks.csv", getFileTimestamp()))
+	outputFile, err := os.Create(outputFileName)
+	checkError(err)
+	writer := bufio.NewWriter(outputFile)
+
+	writer.WriteString("Player,Card,Change\n")
+	for _, p := range pools {
+		if p.previousUri == "" {
+			continue
+		}
+
+		// Compare by lower case name, since sealeddeck.tech's casing doesn't always match scryfall's
+		changes := make(map[string]int)
+		names := make(map[string]string)
+		for _, ds := range p.cards {
+			changes[strings.ToLower(ds.cardName)] += ds.amount
+			names[strings.ToLower(ds.cardName)] = ds.cardName
+		}
+		previousDeck, err := getCardsFromPool(p.player+" (previous)", p.previousUri)
+		if err != nil {
+			fmt.Fprintf(progress, "Skipping %s's add-pack diff: %v\n", p.player, err)
+			continue
+		}
+		for _, ds := range previousDeck.flatten() {
+			changes[strings.ToLower(ds.cardName)] -= ds.amount
+			if _, ok := names[strings.ToLower(ds.cardName)]; !ok {
+				names[strings.ToLower(ds.cardName)] = ds.cardName
+			}
+		}
+
+		keys := make([]string, 0, len(changes))
+		for key, change := range changes {
+			if change != 0 && !isIgnoredCardName(names[key]) {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			writer.WriteString(fmt.Sprintf("%s,%s,%+d\n", csvQuote(p.player), csvQuote(names[key]), changes[key]))
+		}
+	}
+	writer.Flush()
+}
+
+// Compare how many of the living vs. dead pools have each card.  Cards that are much more common in the dead pools
+// (potential traps) come first, and the ones carrying the living pools come last.
+func (run *Run) processPrevalenceGap(alivePools []PlayerPool, deadPools []PlayerPool) {
+	if len(alivePools) == 0 || len(deadPools) == 0 {
+		return
+	}
+
+	alivePoolsWithCard := countPoolsWithCard(alivePools)
+	deadPoolsWithCard := countPoolsWithCard(deadPools)
+
+	gaps := make([]PrevalenceGap, 0)
+	for cardName := range unionKeys(alivePoolsWithCard, deadPoolsWithCard) {
+		if alivePoolsWithCard[cardName]+deadPoolsWithCard[cardName] < prevalenceGapMinPools {
+			continue
+		}
+		gaps = append(gaps, PrevalenceGap{
+			cardName:   cardName,
+			alivePools: alivePoolsWithCard[cardName],
+			alivePct:   100 * float64(alivePoolsWithCard[cardName]) / float64(len(alivePools)),
+			deadPools:  deadPoolsWithCard[cardName],
+			deadPct:    100 * float64(deadPoolsWithCard[cardName]) / float64(len(deadPools)),
+		})
+	}
+	sort.Slice(gaps, func(i, j int) bool {
+		gi, gj := gaps[i].deadPct-gaps[i].alivePct, gaps[j].deadPct-gaps[j].alivePct
+		if gi != gj {
+			return gi > gj
+		}
+		return gaps[i].cardName < gaps[j].cardName
+	})
+
+	outputFileName := filepath.Join(run.config.OutputPath, fmt.Sprintf("ASL_%s_prevalencegap.csv", getFileTimestamp()))
+	outputFile, err := os.Create(outputFileName)
+	checkError(err)
+	writer := bufio.NewWriter(outputFile)
+
+	writer.WriteString("Name,AlivePools,AlivePct,DeadPools,DeadPct,Gap\n")
+	for _, g := range gaps {
+		writer.WriteString(fmt.Sprintf("%s,%d,%.1f,%d,%.1f,%.1f\n", csvQuote(g.cardName), g.alivePools, g.alivePct, g.deadPools, g.deadPct, g.deadPct-g.alivePct))
+	}
+	writer.Flush()
+}
+
+// Take today's look at each of the watched cards, add it to the card's history in the database, and write out the whole history.
+// A rerun on the same day replaces that day's snapshot.
+func (run *Run) processWatchCards(db CardStore, pools []PlayerPool) {
+	if len(watchCardNames) == 0 {
+		return
+	}
+
+	outputFileName := filepath.Join(run.config.OutputPath, fmt.Sprintf("ASL_%s_watchcards.csv", getFileTimestamp()))
+	outputFile, err := os.Create(outputFileName)
+	checkError(err)
+	writer := bufio.NewWriter(outputFile)
+
+	writer.WriteString("Name,Date,AlivePools,DeadPools,Wins,Losses,WinPct\n")
+	for _, cardName := range watchCardNames {
+		snapshot := WatchCardSnapshot{Date: nowFunc().Format("2006-01-02")}
+		for _, p := range pools {
+			if !p.hasCard(cardName) {
+				continue
+			}
+			if p.isAlive {
+				snapshot.AlivePools += 1
+			} else {
+				snapshot.DeadPools += 1
+			}
+			snapshot.Wins += p.wins
+			snapshot.Losses += p.losses
+		}
+		if snapshot.Wins+snapshot.Losses > 0 {
+			snapshot.WinPct = 100 * float64(snapshot.Wins) / float64(snapshot.Wins+snapshot.Losses)
+		}
+
+		// Swap today's snapshot into the history, and save it for next time
+		var history []WatchCardSnapshot
+		var dbKey = "watch_" + strings.ToLower(cardName)
+		if historyJson, err := dbGet(db, dbKey); err == nil {
+			json.Unmarshal([]byte(historyJson), &history)
+		}
+		if len(history) > 0 && history[len(history)-1].Date == snapshot.Date {
+			history = history[:len(history)-1]
+		}
+		history = append(history, snapshot)
+		historyJson, err := json.Marshal(history)
+		checkError(err)
+		checkError(dbSet(db, dbKey, string(historyJson)))
+
+		for _, s := range history {
+			writer.WriteString(fmt.Sprintf("%s,%s,%d,%d,%d,%d,%.1f\n", csvQuote(cardName), s.Date, s.AlivePools, s.DeadPools, s.Wins, s.Losses, s.WinPct))
+		}
+	}
+	writer.Flush()
+}
+
+// Does the pool have at least one copy of the card?
+func (pool *PlayerPool) hasCard(cardName string) bool {
+	for _, ds := range pool.cards {
+		if strings.EqualFold(ds.cardName, cardName) {
+			return true
+		}
+	}
+	return false
+}
+
+// How many of the pools have each card (at least one copy, basics and injected cards aside)
+func countPoolsWithCard(pools []PlayerPool) map[string]int {
+	poolsWithCard := make(map[string]int)
+	for _, p := range pools {
+		for _, ds := range p.cards {
+			if !ds.isIgnored() {
+				poolsWithCard[ds.cardName] += 1
+			}
+		}
+	}
+	return poolsWithCard
+}
+
+// All of the keys that show up in either map
+func unionKeys(a map[string]int, b map[string]int) map[string]struct{} {
+	keys := make(map[string]struct{})
+	for k := range a {
+		keys[k] = struct{}{}
+	}
+	for k := range b {
+		keys[k] = struct{}{}
+	}
+	return keys
+}
+
+// Rank the teams by how strong their living pools are (and then by record).  Players without a team (or unaffiliated ones) get an unranked row at the bottom.
+// Note: relies on the facts from processFunFacts
+func (run *Run) processTeamSummary(pools []PlayerPool) {
+	teams := make(map[string]*TeamSummary)
+	var hasTeams = false
+	for _, p := range pools {
+		var team = p.team
+		if team == "" {
+			team = unaffiliatedTeamName
+		}
+		if team != unaffiliatedTeamName {
+			hasTeams = true
+		}
+		if _, ok := teams[team]; !ok {
+			teams[team] = &TeamSummary{team: team}
+		}
+		t := teams[team]
+		t.players += 1
+		t.wins += p.wins
+		t.losses += p.losses
+		if p.isAlive {
+			t.livingPlayers += 1
+			t.livingStrength += p.stats.Strength
+			t.livingBombs += p.stats.Bombs
+		}
+	}
+	if !hasTeams {
+		return
+	}
+
+	summaries := make([]*TeamSummary, 0, len(teams))
+	for _, t := range teams {
+		if t.team != unaffiliatedTeamName {
+			summaries = append(summaries, t)
+		}
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].livingStrength != summaries[j].livingStrength {
+			return summaries[i].livingStrength > summaries[j].livingStrength
+		}
+		return summaries[i].winPct() > summaries[j].winPct()
+	})
+
+	outputFileName := filepath.Join(run.config.OutputPath, fmt.Sprintf("ASL_%s_teams.csv", getFileTimestamp()))
+	outputFile, err := os.Create(outputFileName)
+	checkError(err)
+	writer := bufio.NewWriter(outputFile)
+
+	writer.WriteString("Rank,Team,Players,LivingPlayers,LivingStrength,AvgLivingStrength,Wins,Losses,WinPct,LivingBombs,Eliminated\n")
+	for i, t := range summaries {
+		writeTeamSummaryRow(writer, strconv.Itoa(i+1), t)
+	}
+	if t, ok := teams[unaffiliatedTeamName]; ok {
+		writeTeamSummaryRow(writer, "", t)
+	}
+	writer.Flush()
+}
+
+// One line of the team summary
+func writeTeamSummaryRow(writer *bufio.Writer, rank string, t *TeamSummary) {
+	var avgStrength = 0.0
+	if t.livingPlayers > 0 {
+		avgStrength = t.livingStrength / float64(t.livingPlayers)
+	}
+	writer.WriteString(fmt.Sprintf("%s,%s,%d,%d,%.1f,%.1f,%d,%d,%.1f,%d,%d\n", rank, csvQuote(t.team), t.players, t.livingPlayers, t.livingStrength, avgStrength, t.wins, t.losses, t.winPct()*100, t.livingBombs, t.players-t.livingPlayers))
+}
+
+// Which of the current set's cards get taken much later (underrated) or earlier (overrated) than their win rate says they should?
+// Cards are ranked by ALSA and by GIH WR, and the ones with the biggest gap between the two ranks are the outliers.
+func (run *Run) processPickOutliers(db CardStore) {
+	// Add the decks back up into one win rate (weighted by games) and keep the pick data that has the most picks behind it
+	var wins = make(map[string]float64)
+	var games = make(map[string]int)
+	var picks = make(map[string]CardPick)
+	var rarities = make(map[string]string)
+	for _, deckId := range run.getDecks(run.currentSet) {
+		cp, err := run.getCardPerformanceData(db, run.currentSet, setPerformanceFormat, deckId, false)
+		if err != nil {
+			continue
+		}
+		for _, cardData := range cp {
+			wins[cardData.Name] += cardData.EverDrawnWinRate * float64(cardData.EverDrawnGameCount)
+			games[cardData.Name] += cardData.EverDrawnGameCount
+			if cardData.PickCount > picks[cardData.Name].pickCount {
+				picks[cardData.Name] = CardPick{avgPick: cardData.AvgPick, pickCount: cardData.PickCount}
+			}
+			rarities[cardData.Name] = cardData.Rarity
+		}
+	}
+
+	outliers := make([]PickOutlier, 0)
+	for cardName, cardGames := range games {
+		if cardGames <= getCardPrevalenceThreshold(rarities[cardName]) || picks[cardName].pickCount == 0 { // filter out rarely played cards
+			continue
+		}
+		outliers = append(outliers, PickOutlier{cardName: cardName, rarity: rarities[cardName], avgPick: picks[cardName].avgPick, winRate: wins[cardName] / float64(cardGames)})
+	}
+	if len(outliers) == 0 {
+		return
+	}
+
+	// Early picks are the ones the drafters rate, so rank on the negative pick
+	pickScores := make([]float64, len(outliers))
+	winRates := make([]float64, len(outliers))
+	for i, o := range outliers {
+		pickScores[i] = -o.avgPick
+		winRates[i] = o.winRate
+	}
+	pickPercentiles := percentileRanks(pickScores)
+	winRatePercentiles := percentileRanks(winRates)
+	for i := range outliers {
+		outliers[i].pickPercentile = pickPercentiles[i]
+		outliers[i].winRatePercentile = winRatePercentiles[i]
+	}
+
+	// Most underrated first, most overrated last
+	sort.Slice(outliers, func(i, j int) bool {
+		gi, gj := outliers[i].winRatePercentile-outliers[i].pickPercentile, outliers[j].winRatePercentile-outliers[j].pickPercentile
+		if gi != gj {
+			return gi > gj
+		}
+		return outliers[i].cardName < outliers[j].cardName
+	})
+
+	outputFileName := filepath.Join(run.config.OutputPath, fmt.Sprintf("ASL_%s_pickoutliers.csv", getFileTimestamp()))
+	outputFile, err := os.Create(outputFileName)
+	checkError(err)
+	writer := bufio.NewWriter(outputFile)
+
+	writer.WriteString("Name,Rarity,AvgPick,GihWR,PickPercentile,WinRatePercentile,Gap\n")
+	for _, o := range outliers {
+		writer.WriteString(fmt.Sprintf("%s,%s,%.2f,%.1f,%d,%d,%d\n", csvQuote(o.cardName), o.rarity, o.avgPick, o.winRate*100, o.pickPercentile, o.winRatePercentile, o.winRatePercentile-o.pickPercentile))
+	}
+	writer.Flush()
+}
+
+// Boil the whole field down to one row: how is the set playing in the league?
+// Note: relies on the facts from processFunFacts
+func (run *Run) processSetSummary(pools []PlayerPool) {
+	if len(pools) == 0 {
+		return
+	}
+
+	var livingPools, bombs = 0, 0
+	var livingStrength = 0.0
+	colours := make(map[string]int)
+	poolsWithBomb := make(map[string]int)
+	for _, p := range pools {
+		if p.isAlive {
+			livingPools += 1
+			livingStrength += p.stats.Strength
+		}
+		bombs += p.stats.Bombs
+		colours["white"] += p.stats.White
+		colours["blue"] += p.stats.Blue
+		colours["black"] += p.stats.Black
+		colours["red"] += p.stats.Red
+		colours["green"] += p.stats.Green
+		colours["gold"] += p.stats.Gold
+		colours["colourless"] += p.stats.Colourless
+		for _, card := range p.cards {
+			if run.isInCuratedSet(card.cardName, run.bombList) {
+				poolsWithBomb[card.cardName] += 1
+			}
+		}
+	}
+
+	var avgStrength = 0.0
+	if livingPools > 0 {
+		avgStrength = livingStrength / float64(livingPools)
+	}
+	var topBomb, topBombPools = "", 0
+	for cardName, count := range poolsWithBomb {
+		if count > topBombPools || (count == topBombPools && cardName < topBomb) {
+			topBomb, topBombPools = cardName, count
+		}
+	}
+
+	outputFileName := filepath.Join(run.config.OutputPath, fmt.Sprintf("ASL_%s_setsummary.csv", getFileTimestamp()))
+	outputFile, err := os.Create(outputFileName)
+	checkError(err)
+	writer := bufio.NewWriter(outputFile)
+
+	writer.WriteString("Set,Pools,LivingPools,AvgLivingStrength,AvgBombs,MostCommonBomb,MostCommonBombPools,W,U,B,R,G,Gold,Colourless\n")
+	writer.WriteString(fmt.Sprintf("%s,%d,%d,%.1f,%.2f,%s,%d,%d,%d,%d,%d,%d,%d,%d\n",
+		run.currentSet, len(pools), livingPools, avgStrength, float64(bombs)/float64(len(pools)), strings.Replace(topBomb, ",", " ", -1), topBombPools,
+		colours["white"], colours["blue"], colours["black"], colours["red"], colours["green"], colours["gold"], colours["colourless"]))
+	writer.Flush()
+}
+
+// Grab one of the curated lists from sealeddeck.tech.  If it can't be had, the facts that use it just come out as zero.
+func getCuratedList(name string, uri string) map[string]DeckSlot {
+	deck, err := getCardsFromPool(name, uri)
+	if err != nil {
+		fmt.Fprintf(progress, "WARNING: Could not load the %s list, so it will be empty: %v\n", name, err)
+		return make(map[string]DeckSlot)
+	}
+	return deck.flatten()
+}
+
+func (run *Run) loadFunFactLists(db CardStore, cardStrengthByDeck map[string]map[string]float64) {
+	// Bombs (>= 63% WR), or the ones 17lands says clear their rarity's bar
+	if len(derivedBombFloors) > 0 {
+		run.bombList = run.deriveBombList(cardStrengthByDeck, derivedBombFloors)
+	} else {
+		run.bombList = getCuratedList("Bombs", bombSealedDeckId)
+	}
 
-	return rawJson, err
-}
+	// Duds (<= 53% WR)
+	run.dudList = getCuratedList("Duds", dudSealedDeckId)
 
-// A dumb little function that looks for a bunch of neato stats
-func processFunFacts(db *badger.DB, pools []PlayerPool) {
+	// Top Commons
+	run.topCommonList = getCuratedList("TopCommons", topCommonDeckId)
 
-	// Load up data about how the cards perform
-	cardStrengthByDeck := loadCardPerformanceData(db) // TODO: all the sets that we care about....
+	// HBG-specific
+	run.topCommanderList = getCuratedList("TopCommanders", topCommanderDeckId)
 
-	// We're going to zip through all of the pools, and add facts about each to them
-	for i := range pools {
-		pools[i].addFacts(cardStrengthByDeck)
+	// The curated lists are just sealeddeck pools, so they can pick up basics along the way
+	if curatedListsSkipBasics {
+		for _, list := range []map[string]DeckSlot{run.bombList, run.dudList, run.topCommonList, run.topCommanderList} {
+			removeBasicLands(list)
+		}
 	}
 
-	// Write out a csv with all of the facts
-	outputFileName := fmt.Sprintf("%s\\ASL_%d_%d_%d_%d_%d_funfacts.csv", outputPath, time.Now().Year(), time.Now().Month(), time.Now().Day(), time.Now().Hour(), time.Now().Minute())
-	outputFile, err := os.Create(outputFileName)
-	checkError(err)
-	writer := bufio.NewWriter(outputFile)
+	// The lists are kept by hand, so make sure someone remembered to update them for this season
+	run.checkCuratedListSets(db, map[string]map[string]DeckSlot{"Bombs": run.bombList, "Duds": run.dudList, "TopCommons": run.topCommonList, "TopCommanders": run.topCommanderList})
+}
 
-	writer.WriteString("Player,Team,IsAlive,Record,Bombs,Duds,TopCommons,W,U,B,R,G,Gold,Colourless,Cmc,NonBasicLand,Commanders,TopCommanders,Playsets,UniqueCards,CostUSD,Strength\n")
-	for _, p := range pools {
-		ff := p.facts
-		writer.WriteString(fmt.Sprintf("%s,%s,%t,%s,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d\n",
-			p.player, p.team, p.isAlive, p.record, ff["bombs"], ff["duds"], ff["topcommons"], ff["white"], ff["blue"], ff["black"], ff["red"], ff["green"], ff["gold"], ff["colourless"],
-			ff["cmc"], ff["nonbasicland"], ff["commanders"], ff["topCommanders"], ff["playsets"], ff["uniqueCards"], ff["costUSD"], ff["strength"]))
+// Build a bomb list out of the 17lands data: every card whose best GIH WR clears the floor for its rarity.
+// Commons win less than rares do, so a single bar would be all rares.  Rarities without a floor never count.
+func (run *Run) deriveBombList(cardStrengthByDeck map[string]map[string]float64, floors map[string]float64) map[string]DeckSlot {
+	bombs := make(map[string]DeckSlot)
+	for _, strengthMap := range cardStrengthByDeck {
+		for cardName := range strengthMap {
+			floor, ok := floors[run.cardRarities[cardName]]
+			if !ok {
+				continue
+			}
+			if getBestWinRate(cardStrengthByDeck, cardName)*100 >= floor {
+				bombs[cardName] = DeckSlot{amount: 1, cardName: cardName}
+			}
+		}
 	}
-	writer.Flush()
+	fmt.Fprintf(progress, "Derived %d bombs from the 17lands data\n", len(bombs))
+	return bombs
 }
 
-func loadFunFactLists(db *badger.DB) {
-	// Bombs (>= 63% WR)
-	bombList = getCardsFromPool("Bombs", bombSealedDeckId).flatten()
+// Parse rarity floors like common:58,uncommon:60,rare:62,mythic:62
+func parseRarityFloors(value string) (map[string]float64, error) {
+	floors := make(map[string]float64)
+	for _, part := range strings.Split(value, ",") {
+		pieces := strings.Split(strings.TrimSpace(part), ":")
+		if len(pieces) != 2 {
+			return nil, errors.New(fmt.Sprintf("Expected rarity:winrate, got: %s", part))
+		}
+		floor, err := strconv.ParseFloat(pieces[1], 64)
+		if err != nil || floor < 0 || floor > 100 {
+			return nil, errors.New(fmt.Sprintf("Bad win rate for %s: %s", pieces[0], pieces[1]))
+		}
+		floors[strings.ToLower(pieces[0])] = floor
+	}
+	return floors, nil
+}
 
-	// Duds (<= 53% WR)
-	dudList = getCardsFromPool("Duds", dudSealedDeckId).flatten()
+// Warn (loudly) about any curated list where most of the cards we know about aren't from the sets in the pools, which
+// usually means the list is still last season's.  Only cards that are already cached are checked.
+func (run *Run) checkCuratedListSets(db CardStore, lists map[string]map[string]DeckSlot) {
+	for listName, list := range lists {
+		var known, inSets = 0, 0
+		for name := range list {
+			if isIgnoredCardName(name) {
+				continue
+			}
+			cardJson, err := dbGet(db, getCardDbKey(name))
+			if err != nil {
+				continue
+			}
+			card := new(ScryfallCard)
+			if json.Unmarshal([]byte(cardJson), &card) != nil {
+				continue
+			}
 
-	// Top Commons
-	topCommonList = getCardsFromPool("TopCommons", topCommonDeckId).flatten()
+			known += 1
+			for setCode := range run.setsInPools {
+				if card.isFromSet(setCode) {
+					inSets += 1
+					break
+				}
+			}
+		}
 
-	// HBG-specific
-	topCommanderList = getCardsFromPool("TopCommanders", topCommanderDeckId).flatten()
+		if known > 0 && float64(inSets)/float64(known) < curatedListMinInSetShare {
+			fmt.Fprintf(progress, "\n*** WARNING: only %d of the %d known cards in the %s list are from the sets in the pools.  Has it been updated for %s? ***\n\n", inSets, known, listName, run.currentSet)
+		}
+	}
+}
+
+// Drop any basic lands (and injected cards) from a flattened (name-only) list of cards
+func removeBasicLands(cards map[string]DeckSlot) {
+	for name := range cards {
+		if isIgnoredCardName(name) {
+			delete(cards, name)
+		}
+	}
 }
 
-func (pool *PlayerPool) addFacts(cardStrengthByDeck map[string]map[string]float64) {
+func (pool *PlayerPool) addFacts(run *Run, cardStrengthByDeck map[string]map[string]float64) {
 
 	// Always fun
 	var bombs = 0
+	var bombColours = make(map[string]bool) // colours with at least one bomb (gold bombs count for each of their colours)
 	var duds = 0
 	var topCommons = 0
 	var whiteCard = 0
@@ -489,21 +3157,53 @@ func (pool *PlayerPool) addFacts(cardStrengthByDeck map[string]map[string]float6
 	var greenCard = 0
 	var goldCard = 0
 	var colourless = 0
+	var colourlessNonArtifact = 0
+	var playableAnywhere = 0
+	var pairPlayables = make(map[string]int) // coloured, non-land cards that fit each colour pair (gold cards count for every pair they fit)
 	var nonBasicLand = 0
 	var playsets = 0
-	var strength = 0
+	var strength = 0.0
 	var cmc = 0.0
+	var curve [8]int                     // non-land cards at each mana value, with 7+ in the last bucket
+	var cardTypes = make(map[string]int) // by front face card type (an artifact creature counts as both)
 	var costUSD = 0.0
+	var cardValues = make([]float64, 0)
+	var pricelessCards = make([]string, 0) // no usable price from scryfall, so they add nothing to costUSD
 	var uniqueCards = 0
 
+	// 17lands-based quality of the cards (only counting cards that have data)
+	var winRateTotal = 0.0
+	var winRateCards = 0
+	var pickTotal = 0.0
+	var pickCards = 0
+
 	// League-specific
 	var commanders = 0
 	var topCommanders = 0
 
-	// Drop the basic lands (and command towers) and gather facts about the cards in the pool.
+	var evasion = 0
+	var combatTricks = 0
+	var manaAccelerants = 0
+	var efficientPlayables = 0
+	var playables = 0
+	var nonBasicCards = 0                         // every copy of every card, other than basics & injected cards
+	var redundancyClusters = make(map[string]int) // cards that look interchangeable: same cmc, colours & primary type
+
+	// Removal, in total and by colour
+	var removal = 0
+	var whiteRemoval = 0
+	var blueRemoval = 0
+	var blackRemoval = 0
+	var redRemoval = 0
+	var greenRemoval = 0
+
+	// Optionally cap the cards that count toward the pool's shape, so that a ballooned pool doesn't skew the comparison
+	var factCards = pool.getTopCardNames(cardStrengthByDeck, factCardsCap)
+
+	// Drop the basic lands (and injected cards, like command towers) and gather facts about the cards in the pool.
 	for _, card := range pool.cards {
 		// Filter out the basic lands
-		if !card.isBasicLand() {
+		if !card.isIgnored() {
 
 			var copies = card.amount
 			if isSingletonLeague {
@@ -512,48 +3212,113 @@ func (pool *PlayerPool) addFacts(cardStrengthByDeck map[string]map[string]float6
 
 			// We're working with a de-dup'd list, so increment here.
 			uniqueCards += 1
+			nonBasicCards += copies
 
 			// Bombs
-			if isInCuratedSet(card.cardName, bombList) {
+			if run.isInCuratedSet(card.cardName, run.bombList) {
 				bombs += copies
+				for _, colour := range []string{"W", "U", "B", "R", "G"} {
+					if card.isColour(colour, false) {
+						bombColours[colour] = true
+					}
+				}
 			}
 
 			// Duds
-			if isInCuratedSet(card.cardName, dudList) {
+			if run.isInCuratedSet(card.cardName, run.dudList) {
 				duds += copies
 			}
 
 			// Top Commons
-			if isInCuratedSet(card.cardName, topCommonList) {
+			if run.isInCuratedSet(card.cardName, run.topCommonList) {
 				topCommons += copies
 			}
 
+			// With a fact-card cap, only the pool's best cards count toward its shape (colours, mana value, card types)
+			var shapeCopies, shapeAmount = copies, card.amount
+			if factCards != nil && !factCards[card.cardName] {
+				shapeCopies, shapeAmount = 0, 0
+			}
+
 			// Cards of each colour
 			if card.isColour("W", true) {
-				whiteCard += copies
+				whiteCard += shapeCopies
 			}
 			if card.isColour("U", true) {
-				blueCard += copies
+				blueCard += shapeCopies
 			}
 			if card.isColour("B", true) {
-				blackCard += copies
+				blackCard += shapeCopies
 			}
 			if card.isColour("R", true) {
-				redCard += copies
+				redCard += shapeCopies
 			}
 			if card.isColour("G", true) {
-				greenCard += copies
+				greenCard += shapeCopies
 			}
 			if card.isMultiColour() {
-				goldCard += copies
+				goldCard += shapeCopies
 			}
 			if card.isColourless() && !card.isCardType("Land") {
-				colourless += copies
+				colourless += shapeCopies
+			}
+			if card.isColourless() && !card.isCardType("Land") && !card.isCardType("Artifact") { // Eldrazi, devoid, etc.
+				colourlessNonArtifact += shapeCopies
+			}
+			if card.isColorlessPlayableAnywhere() {
+				playableAnywhere += shapeCopies
+			}
+			if !card.isColourless() && !card.isCardType("Land") {
+				for _, pair := range mtg2CDecks {
+					if card.fitsDeck(pair) {
+						pairPlayables[pair] += shapeCopies
+					}
+				}
+			}
+
+			// Removal for each colour (gold removal counts toward each of its colours, but only once toward the total)
+			if card.isRemoval() {
+				removal += copies
+				if card.isColour("W", false) {
+					whiteRemoval += copies
+				}
+				if card.isColour("U", false) {
+					blueRemoval += copies
+				}
+				if card.isColour("B", false) {
+					blackRemoval += copies
+				}
+				if card.isColour("R", false) {
+					redRemoval += copies
+				}
+				if card.isColour("G", false) {
+					greenRemoval += copies
+				}
+			}
+
+			// Evasive creatures
+			if card.isCardType("Creature") && card.isEvasive() {
+				evasion += shapeCopies
+			}
+
+			// Ramp & fixing that isn't a land
+			if !card.isCardType("Land") && card.isManaAccelerant() {
+				manaAccelerants += copies
+			}
+
+			// Combat tricks
+			if card.isCardType("Instant") && card.isCombatTrick() {
+				combatTricks += shapeCopies
+			}
+
+			// Redundancy
+			if !card.isCardType("Land") {
+				redundancyClusters[card.getRedundancyKey()] += copies
 			}
 
 			// Non-basics
 			if card.isCardType("Land") && !card.isBasicLand() {
-				nonBasicLand += copies
+				nonBasicLand += shapeCopies
 			}
 
 			// A playset (or more) of a card
@@ -562,18 +3327,54 @@ func (pool *PlayerPool) addFacts(cardStrengthByDeck map[string]map[string]float6
 			}
 
 			// $$$$
-			cardCost, _ := strconv.ParseFloat(card.card.Prices.Usd, 64)
+			cardCost, ok := card.card.getPriceUSD()
+			if !ok {
+				pricelessCards = append(pricelessCards, card.cardName)
+			}
 			costUSD += float64(card.amount) * cardCost
+			cardValues = append(cardValues, float64(card.amount)*cardCost)
 
 			// Total mana value of the pool
-			cmc += float64(card.amount) * card.card.Cmc
+			cmc += float64(shapeAmount) * card.card.Cmc
+
+			// And how it's spread out across the curve (spells only)
+			if !card.isCardType("Land") {
+				curve[int(math.Min(math.Floor(card.card.Cmc), float64(len(curve)-1)))] += shapeCopies
+			}
+
+			// Card types
+			for _, cardType := range []string{"Creature", "Instant", "Sorcery", "Artifact", "Enchantment", "Planeswalker"} {
+				if card.countsAsCardType(cardType) {
+					cardTypes[cardType] += shapeCopies
+				}
+			}
+
+			// How well the card wins, and how early it gets taken
+			if wr := getBestWinRate(cardStrengthByDeck, card.cardName); wr > 0 {
+				winRateTotal += float64(copies) * wr
+				winRateCards += copies
+
+				// Cheap cards that win are what a good early game is made of
+				if !card.isCardType("Land") && card.card.Cmc <= efficientPlayableMaxCmc && wr*100 >= efficientPlayableWinRate {
+					efficientPlayables += copies
+				}
+
+				// How deep the pool's playables run
+				if wr*100 >= playableWinRate {
+					playables += copies
+				}
+			}
+			if pick, ok := run.cardPicks[card.cardName]; ok && pick.pickCount > 0 {
+				pickTotal += float64(copies) * pick.avgPick
+				pickCards += copies
+			}
 
 			// Commanders are legendary creatures
 			if card.isCardType("Legendary Creature") {
 				commanders += 1 // card.amount  (don't count multiples)
 			}
 			// OP commanders
-			if isInCuratedSet(card.cardName, topCommanderList) {
+			if run.isInCuratedSet(card.cardName, run.topCommanderList) {
 				topCommanders += 1 // don't count multiples
 			}
 
@@ -581,52 +3382,223 @@ func (pool *PlayerPool) addFacts(cardStrengthByDeck map[string]map[string]float6
 	}
 
 	// Now try to determine the deck strength
-	strength = pool.calculateStrength(cardStrengthByDeck)
+	strength = pool.calculateStrength(run, cardStrengthByDeck)
 
 	// Add all the facts to the pool
-	pool.facts["bombs"] = bombs
-	pool.facts["duds"] = duds
-	pool.facts["topcommons"] = topCommons
-	pool.facts["white"] = whiteCard
-	pool.facts["blue"] = blueCard
-	pool.facts["black"] = blackCard
-	pool.facts["red"] = redCard
-	pool.facts["green"] = greenCard
-	pool.facts["gold"] = goldCard
-	pool.facts["colourless"] = colourless
-	pool.facts["cmc"] = int(math.Round(cmc))
-	pool.facts["nonbasicland"] = nonBasicLand
-	pool.facts["commanders"] = commanders
-	pool.facts["topCommanders"] = topCommanders
-	pool.facts["playsets"] = playsets
-	pool.facts["uniqueCards"] = uniqueCards
-	pool.facts["costUSD"] = int(math.Round(costUSD))
-	pool.facts["strength"] = 0
+	stats := &pool.stats
+	stats.Bombs = bombs
+	stats.BombColors = len(bombColours)
+	stats.Duds = duds
+	stats.TopCommons = topCommons
+	stats.White = whiteCard
+	stats.Blue = blueCard
+	stats.Black = blackCard
+	stats.Red = redCard
+	stats.Green = greenCard
+	stats.Gold = goldCard
+	stats.Colourless = colourless
+	stats.ColorlessNonArtifact = colourlessNonArtifact
+	stats.PlayableAnywhere = playableAnywhere
+
+	// The colour pair with the most playables is the deck the pool is pointing toward
+	for _, pair := range mtg2CDecks {
+		if pairPlayables[pair] > stats.LikelyPairCount {
+			stats.LikelyPair = pair
+			stats.LikelyPairCount = pairPlayables[pair]
+		}
+	}
+	stats.Cmc = cmc
+	stats.Curve = curve
+	stats.Creatures = cardTypes["Creature"]
+	stats.Instants = cardTypes["Instant"]
+	stats.Sorceries = cardTypes["Sorcery"]
+	stats.Artifacts = cardTypes["Artifact"]
+	stats.Enchantments = cardTypes["Enchantment"]
+	stats.Planeswalkers = cardTypes["Planeswalker"]
+	stats.NonBasicLand = nonBasicLand
+	stats.Commanders = commanders
+	stats.TopCommanders = topCommanders
+	stats.Playsets = playsets
+	stats.UniqueCards = uniqueCards
+	stats.CostUSD = costUSD
+	stats.PricelessCards = len(pricelessCards)
+	if len(pricelessCards) > 0 {
+		sort.Strings(pricelessCards)
+		fmt.Fprintf(progress, "%s has %d card(s) without a price, left out of the pool's value: %s\n", pool.player, len(pricelessCards), strings.Join(pricelessCards, "; "))
+	}
+
+	// How much of the value is tied up in the priciest card (and the priciest three)?
+	sort.Slice(cardValues, func(i, j int) bool {
+		return cardValues[i] > cardValues[j]
+	})
+	var topCardValue, top3Value = 0.0, 0.0
+	for i := 0; i < 3 && i < len(cardValues); i++ {
+		if i == 0 {
+			topCardValue = cardValues[i]
+		}
+		top3Value += cardValues[i]
+	}
+	if costUSD > 0 {
+		stats.TopCardValuePct = 100 * topCardValue / costUSD
+		stats.Top3ValuePct = 100 * top3Value / costUSD
+	}
+	stats.Removal = removal
+	stats.WhiteRemoval = whiteRemoval
+	stats.BlueRemoval = blueRemoval
+	stats.BlackRemoval = blackRemoval
+	stats.RedRemoval = redRemoval
+	stats.GreenRemoval = greenRemoval
+	stats.Evasion = evasion
+	stats.CombatTricks = combatTricks
+	stats.ManaAccelerants = manaAccelerants
+	stats.EfficientPlayables = efficientPlayables
+	if nonBasicCards > 0 {
+		stats.PlayablePct = 100 * float64(playables) / float64(nonBasicCards)
+	}
+	for _, size := range redundancyClusters {
+		if size >= 2 {
+			stats.RedundantGroups += 1
+		}
+	}
+	if winRateCards > 0 {
+		stats.AvgWinRate = winRateTotal / float64(winRateCards)
+	}
+	if pickCards > 0 {
+		stats.AvgPick = pickTotal / float64(pickCards)
+	}
+	stats.FirstPickQuality = pool.calculateFirstPickQuality(run, cardStrengthByDeck)
+	stats.BestCommon, stats.BestCommonWR = pool.getBestCommon(run, cardStrengthByDeck)
+	stats.RawStrength = strength
 	if pool.isAlive {
-		pool.facts["strength"] = strength
+		stats.Strength = strength
+		if curvePenalty > 0 {
+			stats.CurveStrength = pool.calculateCurveStrength()
+		}
+	}
+}
+
+// Count up (and list) the cards in the pool that are banned or not legal in the format, so the judges can chase them up
+func (pool *PlayerPool) addLegalityFacts(format string) {
+	if format == "" {
+		return
+	}
+
+	illegalCards := make([]string, 0)
+	for _, c := range pool.cards {
+		if c.isBasicLand() {
+			continue
+		}
+		legality := c.card.getLegality(format)
+		if legality == "banned" || legality == "not_legal" {
+			illegalCards = append(illegalCards, c.cardName)
+		}
+	}
+	sort.Strings(illegalCards)
+
+	pool.stats.IllegalCards = len(illegalCards)
+	pool.stats.IllegalCardNames = strings.Join(illegalCards, "; ")
+	if len(illegalCards) > 0 {
+		fmt.Fprintf(progress, "%s has %d card(s) that aren't legal in %s: %s\n", pool.player, len(illegalCards), format, pool.stats.IllegalCardNames)
+	}
+}
+
+// Compare where each pool ranks by strength against where it ranks by record.
+// A positive luckIndex means the pool is out-performing its cards, a negative one means it's under-performing them.
+// Note: dead pools have their reported strength zeroed, so use the raw strength here.
+// Pools that we have no card data for are left out (and left at 0), since their strength is meaningless.
+func addLuckFacts(pools []PlayerPool) {
+	indexes := make([]int, 0)
+	strengths := make([]float64, 0)
+	winRates := make([]float64, 0)
+	for i, p := range pools {
+		if !p.hasCardData() {
+			continue
+		}
+		indexes = append(indexes, i)
+		strengths = append(strengths, p.stats.RawStrength)
+		winRates = append(winRates, p.winRate())
+	}
+
+	strengthPercentiles := percentileRanks(strengths)
+	recordPercentiles := percentileRanks(winRates)
+	for j, i := range indexes {
+		pools[i].stats.StrengthPercentile = strengthPercentiles[j]
+		pools[i].stats.RecordPercentile = recordPercentiles[j]
+		pools[i].stats.LuckIndex = recordPercentiles[j] - strengthPercentiles[j]
+	}
+}
+
+// Blend the pool's average win rate with its average pick position into a single 0-100 quality score.
+// Both are normalized across the field first (min-max), so that the weight means what it says.  A lower pick is better.
+// Pools without any pick data are left out (and left unscored), since an average pick of 0 would look like the best pool there is.
+func addQualityFacts(pools []PlayerPool) {
+	indexes := make([]int, 0)
+	winRates := make([]float64, 0)
+	picks := make([]float64, 0)
+	for i, p := range pools {
+		if !p.hasPickData() {
+			continue
+		}
+		indexes = append(indexes, i)
+		winRates = append(winRates, p.stats.AvgWinRate)
+		picks = append(picks, p.stats.AvgPick)
+	}
+
+	normalizedWinRates := normalize(winRates)
+	normalizedPicks := normalize(picks)
+	for j, i := range indexes {
+		quality := qualityWinRateWeight*normalizedWinRates[j] + (1-qualityWinRateWeight)*(1-normalizedPicks[j])
+		pools[i].stats.QualityScore = int(math.Round(quality * 100))
+	}
+}
+
+// Did 17lands have an average pick for any of the pool's cards?
+func (pool *PlayerPool) hasPickData() bool {
+	return pool.stats.AvgPick > 0
+}
+
+// The fraction of games played that the pool has won
+func (pool *PlayerPool) winRate() float64 {
+	if pool.wins+pool.losses == 0 {
+		return 0
 	}
+	return float64(pool.wins) / float64(pool.wins+pool.losses)
 }
 
 // Algorithm for Strength:
 // For each colour pair (deck):
 //     Pick the top X GIH WR cards and sum their WRs
 // Pick the top 3 colour pairs and return a weighted strength (100% of 1st, 80% of 2nd, 40% of 3rd)
-func (pool *PlayerPool) calculateStrength(cardStrengthByDeck map[string]map[string]float64) int {
-	var strength = 0.0
+func (pool *PlayerPool) calculateStrength(run *Run, cardStrengthByDeck map[string]map[string]float64) float64 {
 	var deckStrengths = make(map[string]float64)
+	pool.deckTopCards = make(map[string][]CardStrength)
 
 	// Walk through the colour pairs
-	for _, deckId := range getDecks(currentSet) {
+	for _, deckId := range run.getDecks(run.currentSet) {
 		var strengthMap = cardStrengthByDeck[deckId]
 		var deckStrength = 0.0
 
 		// Add strength objects for all cards in the pool (break multiples into separate entries)
 		var cardStrengths = make([]CardStrength, 0)
 		for _, c := range pool.cards {
+			// Optionally drop the off-colour chaff so it can't pad out the archetype
+			if onColourStrength && !c.fitsDeck(deckId) {
+				continue
+			}
+
+			// Optionally drop anything that leaked in from another set
+			if currentSetOnlyStrength && !c.card.isFromSet(run.currentSet) {
+				continue
+			}
+
 			strength, ok := strengthMap[c.cardName]
+			strength *= run.getContestedColourFactor(c.card)
 			// one entry per copy (unless singleton)
 			var copies = c.amount
-			if isSingletonLeague {
+			if maindeckOnlyStrength {
+				copies = c.deckAmount
+			}
+			if isSingletonLeague && copies > 0 {
 				copies = 1
 			}
 			for i := 0; i < copies; i++ {
@@ -639,57 +3611,393 @@ func (pool *PlayerPool) calculateStrength(cardStrengthByDeck map[string]map[stri
 
 		}
 
-		// Now sort by strength
-		sort.Slice(cardStrengths, func(i, j int) bool {
-			return cardStrengths[i].strength > cardStrengths[j].strength
-		})
+		// Now sort by strength
+		sort.Slice(cardStrengths, func(i, j int) bool {
+			return cardStrengths[i].strength > cardStrengths[j].strength
+		})
+
+		// Sum the top X results
+		var maxIndex = pool.getStrengthCardsToConsider()
+		if len(cardStrengths) < maxIndex { // protect from weeird edge case of a tiny pool
+			maxIndex = len(cardStrengths)
+		}
+		for _, cs := range cardStrengths[0:maxIndex] {
+			deckStrength += cs.strength
+		}
+		deckStrengths[deckId] = deckStrength
+		pool.deckTopCards[deckId] = cardStrengths[0:maxIndex]
+	}
+	pool.deckStrengths = deckStrengths
+
+	return getBlendedStrength(deckStrengths)
+}
+
+// Blend each archetype's strength into the pool's strength
+func getBlendedStrength(deckStrengths map[string]float64) float64 {
+	// Take the average of the top 3 strongest decks
+	v := make([]float64, 0, len(deckStrengths))
+	for _, val := range deckStrengths {
+		v = append(v, val)
+	}
+	sort.Slice(v, func(i, j int) bool {
+		return v[i] > v[j]
+	})
+
+	// Take 100% of the best deck, 80% of the second best deck, and 40% of the third best deck to get total strength of the pool.
+	// A set with fewer than 3 archetypes just blends the ones it has.
+	var strength = 0.0
+	for i, weight := range deckStrengthWeights {
+		if i < len(v) {
+			strength += v[i] * weight
+		}
+	}
+	return strength * 100.0
+}
+
+// Re-sum each archetype's top cards with the high drops past the curve budget marked down, then blend them like the normal strength.
+// The weakest high drops are the ones over budget, since they'd be the first cut from the deck.
+func (pool *PlayerPool) calculateCurveStrength() float64 {
+	cmcByName := make(map[string]float64)
+	for _, c := range pool.cards {
+		if c.card != nil {
+			cmcByName[c.cardName] = c.card.Cmc
+		}
+	}
+
+	deckStrengths := make(map[string]float64)
+	for deckId, topCards := range pool.deckTopCards {
+		var budget = int(math.Round(float64(len(topCards)) * curveHighDropShare))
+		var highDrops = 0
+		var deckStrength = 0.0
+		for _, cs := range topCards { // strongest first
+			if cmcByName[cs.cardName] >= curveHighDropCmc {
+				highDrops += 1
+				if highDrops > budget {
+					deckStrength += cs.strength * (1 - curvePenalty)
+					continue
+				}
+			}
+			deckStrength += cs.strength
+		}
+		deckStrengths[deckId] = deckStrength
+	}
+
+	return getBlendedStrength(deckStrengths)
+}
+
+// What share of the coloured cards in the living pools are each colour (gold cards count toward each of their colours)
+func getFieldColourShares(pools []PlayerPool) map[string]float64 {
+	var counts = make(map[string]int)
+	var total = 0
+	for _, p := range pools {
+		if !p.isAlive {
+			continue
+		}
+		for _, c := range p.cards {
+			if c.card == nil || c.isIgnored() || c.isCardType("Land") {
+				continue
+			}
+			for _, colour := range c.card.ColorIdentity {
+				counts[colour] += c.amount
+				total += c.amount
+			}
+		}
+	}
+
+	shares := make(map[string]float64)
+	for colour, count := range counts {
+		shares[colour] = float64(count) / float64(total)
+	}
+	return shares
+}
+
+// How much of a card's strength survives the field fighting over its colours.  A colour drafted at exactly its fair share (1/5) costs nothing,
+// and every 1% over it costs contestedColourWeight% of the card's strength.  Gold cards average their colours, and colourless cards are never touched.
+func (run *Run) getContestedColourFactor(card *ScryfallCard) float64 {
+	if contestedColourWeight <= 0 || card == nil || len(card.ColorIdentity) == 0 || len(run.fieldColourShares) == 0 {
+		return 1
+	}
+
+	var fairShare = 1.0 / 5.0
+	var overDrafted = 0.0
+	for _, colour := range card.ColorIdentity {
+		overDrafted += (run.fieldColourShares[colour] - fairShare) / fairShare
+	}
+	overDrafted /= float64(len(card.ColorIdentity))
+	if overDrafted <= 0 {
+		return 1
+	}
+	return math.Max(0, 1-contestedColourWeight*overDrafted)
+}
+
+// How many of each archetype's cards count toward strength: the top 60 for sealed pools, and the top 23 (a deck's worth of spells) for draft pools
+func (pool *PlayerPool) getStrengthCardsToConsider() int {
+	var poolType = leaguePoolType
+	if poolType == "auto" {
+		var poolSize = 0
+		for _, c := range pool.cards {
+			if !c.isIgnored() {
+				poolSize += c.amount
+			}
+		}
+		poolType = "sealed"
+		if poolSize < draftPoolMaxCards {
+			poolType = "draft"
+		}
+	}
+
+	if poolType == "draft" {
+		return draftDeckStrengthCardsToConsider
+	}
+	return deckStrengthCardsToConsider
+}
+
+// The average pick position of the pool's three strongest cards (by GIH WR).  Lower means the pool's best cards are early picks.
+func (pool *PlayerPool) calculateFirstPickQuality(run *Run, cardStrengthByDeck map[string]map[string]float64) float64 {
+	var cardStrengths = make([]CardStrength, 0)
+	for _, c := range pool.cards {
+		if wr := getBestWinRate(cardStrengthByDeck, c.cardName); wr > 0 && !c.isIgnored() {
+			cardStrengths = append(cardStrengths, CardStrength{c.cardName, wr})
+		}
+	}
+	sort.Slice(cardStrengths, func(i, j int) bool {
+		return cardStrengths[i].strength > cardStrengths[j].strength
+	})
+
+	var pickTotal, picks = 0.0, 0
+	for i := 0; i < 3 && i < len(cardStrengths); i++ {
+		if pick, ok := run.cardPicks[cardStrengths[i].cardName]; ok && pick.pickCount > 0 {
+			pickTotal += pick.avgPick
+			picks += 1
+		}
+	}
+	if picks == 0 {
+		return 0
+	}
+	return pickTotal / float64(picks)
+}
+
+// The names of the pool's N strongest cards (by best GIH WR), or nil if the whole pool fits under N (or N is 0)
+func (pool *PlayerPool) getTopCardNames(cardStrengthByDeck map[string]map[string]float64, n int) map[string]bool {
+	var cardStrengths = make([]CardStrength, 0)
+	for _, c := range pool.cards {
+		if !c.isIgnored() {
+			cardStrengths = append(cardStrengths, CardStrength{c.cardName, getBestWinRate(cardStrengthByDeck, c.cardName)})
+		}
+	}
+	if n <= 0 || len(cardStrengths) <= n {
+		return nil
+	}
+
+	sort.SliceStable(cardStrengths, func(i, j int) bool {
+		return cardStrengths[i].strength > cardStrengths[j].strength
+	})
+	topCards := make(map[string]bool)
+	for _, cs := range cardStrengths[:n] {
+		topCards[cs.cardName] = true
+	}
+	return topCards
+}
+
+// The pool's common with the best GIH WR (and that WR), or an empty name if there are no commons with data
+func (pool *PlayerPool) getBestCommon(run *Run, cardStrengthByDeck map[string]map[string]float64) (string, float64) {
+	var bestName, bestWinRate = "", 0.0
+	for _, c := range pool.cards {
+		if c.isIgnored() || run.cardRarities[c.cardName] != "common" {
+			continue
+		}
+		if wr := getBestWinRate(cardStrengthByDeck, c.cardName); wr > bestWinRate {
+			bestName, bestWinRate = c.cardName, wr
+		}
+	}
+	return bestName, bestWinRate
+}
+
+// Print out how the pool's strength was put together, so that it can be checked by hand
+func (pool *PlayerPool) explainStrength() {
+	fmt.Fprintf(progress, "\nStrength breakdown for %s:\n", pool.player)
+
+	// Strongest decks first
+	deckIds := make([]string, 0, len(pool.deckStrengths))
+	for deckId := range pool.deckStrengths {
+		deckIds = append(deckIds, deckId)
+	}
+	sort.Slice(deckIds, func(i, j int) bool {
+		return pool.deckStrengths[deckIds[i]] > pool.deckStrengths[deckIds[j]]
+	})
+
+	for _, deckId := range deckIds {
+		fmt.Fprintf(progress, "  %s: %.2f (top %d cards)\n", deckId, pool.deckStrengths[deckId], len(pool.deckTopCards[deckId]))
+		for _, cs := range pool.deckTopCards[deckId] {
+			fmt.Fprintf(progress, "      %5.1f%%  %s\n", cs.strength*100, cs.cardName)
+		}
+	}
+
+	var total = 0.0
+	for i := 0; i < len(deckStrengthWeights) && i < len(deckIds); i++ {
+		fmt.Fprintf(progress, "  %s x %.1f = %.2f\n", deckIds[i], deckStrengthWeights[i], pool.deckStrengths[deckIds[i]]*deckStrengthWeights[i])
+		total += pool.deckStrengths[deckIds[i]] * deckStrengthWeights[i]
+	}
+	fmt.Fprintf(progress, "  Strength = %.2f x 100 = %d\n\n", total, int(total*100))
+}
+
+// The best GIH WR the card has across all of the decks, or 0 if we have no data for it
+func getBestWinRate(cardStrengthByDeck map[string]map[string]float64, cardName string) float64 {
+	var best = 0.0
+	for _, strengthMap := range cardStrengthByDeck {
+		if strengthMap[cardName] > best {
+			best = strengthMap[cardName]
+		}
+	}
+	return best
+}
+
+// Grab the valid decks (e.g. RB, UWG)  for the specified set
+func (run *Run) getDecks(setCode string) []string {
+	var mtgDecks = make([]string, 0)
+
+	// Prefer the archetypes file
+	if archetypes, ok := run.archetypesBySet[setCode]; ok {
+		mtgDecks = append(mtgDecks, archetypes.TwoColour...)
+		mtgDecks = append(mtgDecks, archetypes.ThreeColour...)
+		return mtgDecks
+	}
+
+	mtgDecks = append(mtgDecks, mtg2CDecks...)
+	_, ok := seventeenLands3CSets[setCode]
+	if ok {
+		mtgDecks = append(mtgDecks, mtg3CDecks...)
+	}
+	return mtgDecks
+}
+
+// Load the archetypes for each set from a json file (see archetypes.json).  A missing file just means we use the built-in lists.
+func loadArchetypes(fileName string) (map[string]SetArchetypes, error) {
+	archetypes := make(map[string]SetArchetypes)
+	data, err := ioutil.ReadFile(fileName)
+	if errors.Is(err, os.ErrNotExist) {
+		fmt.Fprintln(progress, "No archetypes file found, using the built-in archetypes: ", fileName)
+		return archetypes, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(data, &archetypes)
+	if err != nil {
+		return nil, fmt.Errorf("Could not parse archetypes file %s: %w", fileName, err)
+	}
+
+	// Set codes are upper case everywhere else
+	normalized := make(map[string]SetArchetypes)
+	for setCode, a := range archetypes {
+		normalized[strings.ToUpper(setCode)] = a
+	}
+	return normalized, nil
+}
+
+// Is the card in a list of cards that we've curated for some analysis?
+// The lists are typed up by hand, so if there's no exact match, try the normalized name and then a close match (a typo or two).
+// Those matches get logged so that the list can be fixed, and every answer is remembered since the close match is slow.
+func (run *Run) isInCuratedSet(cardName string, curatedCardNames map[string]DeckSlot) bool {
+	if _, ok := curatedCardNames[cardName]; ok {
+		return true
+	}
+
+	cacheKey := fmt.Sprintf("%p|%s", curatedCardNames, cardName)
+	run.curatedListMatchesMu.Lock()
+	matched, ok := run.curatedListMatches[cacheKey]
+	run.curatedListMatchesMu.Unlock()
+	if ok {
+		return matched
+	}
 
-		// Sum the top X results
-		var maxIndex = deckStrengthCardsToConsider
-		if len(cardStrengths) < deckStrengthCardsToConsider { // protect from weeird edge case of a tiny pool
-			maxIndex = len(cardStrengths)
+	normalizedName := normalizeCardName(cardName)
+	for listName := range curatedCardNames {
+		if normalizeCardName(listName) == normalizedName {
+			fmt.Fprintf(progress, "Curated list has \"%s\", which was matched to %s\n", listName, cardName)
+			matched = true
+			break
 		}
-		for _, cs := range cardStrengths[0:maxIndex] {
-			deckStrength += cs.strength
+	}
+	if !matched {
+		for listName := range curatedCardNames {
+			if getEditDistance(normalizeCardName(listName), normalizedName) <= getTypoAllowance(normalizedName) {
+				fmt.Fprintf(progress, "WARNING: Curated list has \"%s\", which looks like a typo of %s.  Please fix the list!\n", listName, cardName)
+				matched = true
+				break
+			}
 		}
-		deckStrengths[deckId] = deckStrength
 	}
 
-	// Take the average of the top 3 strongest decks
-	v := make([]float64, len(deckStrengths))
-	for _, val := range deckStrengths {
-		v = append(v, val)
-	}
-	sort.Slice(v, func(i, j int) bool {
-		return v[i] > v[j]
-	})
+	run.curatedListMatchesMu.Lock()
+	run.curatedListMatches[cacheKey] = matched
+	run.curatedListMatchesMu.Unlock()
+	return matched
+}
 
-	// Take 100% of the best deck, 80% of the second best deck, and 40% of the third best deck to get total strength of the pool
-	strength = (v[0] + (v[1] * 0.8) + (v[2] * 0.4)) * 100.0
+// Lower case, straight apostrophes and single spaces, so that near-enough names compare equal
+func normalizeCardName(cardName string) string {
+	cardName = strings.ReplaceAll(strings.ToLower(cardName), "’", "'")
+	return strings.Join(strings.Fields(cardName), " ")
+}
 
-	return int(strength)
+// How many typos a name can have and still count as a close match.  Short names get less slack, so that different cards don't match.
+func getTypoAllowance(cardName string) int {
+	switch {
+	case len(cardName) < 6:
+		return 0
+	case len(cardName) < 12:
+		return 1
+	}
+	return 2
 }
 
-// Grab the valid decks (e.g. RB, UWG)  for the specified set
-func getDecks(setCode string) []string {
-	var mtgDecks = make([]string, 0)
-	mtgDecks = append(mtgDecks, mtg2CDecks...)
-	_, ok := seventeenLands3CSets[setCode]
-	if ok {
-		mtgDecks = append(mtgDecks, mtg3CDecks...)
+// The number of single character edits to get from one string to the other (Levenshtein distance)
+func getEditDistance(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
 	}
-	return mtgDecks
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			var cost = 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(minInt(previous[j]+1, current[j-1]+1), previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
 }
 
-// Is the card in a list of cards that we've curated for some analysis?
-func isInCuratedSet(cardName string, curatedCardNames map[string]DeckSlot) bool {
-	_, ok := curatedCardNames[cardName]
-	return ok
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
 }
 
-// Is the card a basic land (or command tower, which sealeddeck.tech inserts sometimes)
+// Is the card a basic land?
 func (ds *DeckSlot) isBasicLand() bool {
-	return ds.card.Name == "Plains" || ds.card.Name == "Island" || ds.card.Name == "Swamp" || ds.card.Name == "Mountain" || ds.card.Name == "Forest" || ds.card.Name == "Command Tower"
+	return isBasicLandName(ds.card.Name)
+}
+
+func isBasicLandName(name string) bool {
+	return name == "Plains" || name == "Island" || name == "Swamp" || name == "Mountain" || name == "Forest"
+}
+
+// Should the card be left out of the facts?  Basic lands always are, along with whatever sealeddeck.tech injects into pools (see -injected-cards).
+func (ds *DeckSlot) isIgnored() bool {
+	return isIgnoredCardName(ds.card.Name)
+}
+
+func isIgnoredCardName(name string) bool {
+	return isBasicLandName(name) || containsString(injectedCardNames, name)
 }
 
 // Is this card the given colour identity?
@@ -708,6 +4016,16 @@ func (ds *DeckSlot) isColour(colour string, mono bool) bool {
 	return false
 }
 
+// Is the card castable in the given deck (e.g. "WU")?  Colourless cards fit everywhere.
+func (ds *DeckSlot) fitsDeck(deckId string) bool {
+	for _, c := range ds.card.ColorIdentity {
+		if !strings.Contains(deckId, c) {
+			return false
+		}
+	}
+	return true
+}
+
 func (ds *DeckSlot) isMultiColour() bool {
 	return len(ds.card.ColorIdentity) > 1 && !ds.isCardType("Land")
 }
@@ -716,11 +4034,86 @@ func (ds *DeckSlot) isColourless() bool {
 	return len(ds.card.ColorIdentity) == 0
 }
 
+// Can the card go in any deck, whatever its colours?  Colourless spells that need actual colourless mana ({C}) don't count, since most decks can't make it.
+func (ds *DeckSlot) isColorlessPlayableAnywhere() bool {
+	if !ds.isColourless() || ds.isCardType("Land") {
+		return false
+	}
+	return !strings.Contains(ds.card.getManaCost(), "{C}") && !strings.Contains(ds.card.getOracleText(), "{c}")
+}
+
+// Does the card's oracle text (on either face) look like creature/planeswalker removal?
+func (ds *DeckSlot) isRemoval() bool {
+	return matchesAny(ds.card.getOracleText(), removalPatterns)
+}
+
+// Does the card's oracle text (on either face) make mana?
+func (ds *DeckSlot) isManaAccelerant() bool {
+	return matchesAny(ds.card.getOracleText(), manaAcceleratorPatterns)
+}
+
+// Does the card's oracle text (on either face) pump or protect a creature?
+func (ds *DeckSlot) isCombatTrick() bool {
+	return matchesAny(ds.card.getOracleText(), combatTrickPatterns)
+}
+
+// Does the card have an evasion keyword, or oracle text that makes it hard to block?
+func (ds *DeckSlot) isEvasive() bool {
+	for _, k := range ds.card.Keywords {
+		for _, evasionKeyword := range evasionKeywords {
+			if strings.EqualFold(fmt.Sprintf("%v", k), evasionKeyword) {
+				return true
+			}
+		}
+	}
+
+	var oracleText = ds.card.getOracleText()
+	for _, phrase := range evasionPhrases {
+		if strings.Contains(oracleText, phrase) {
+			return true
+		}
+	}
+	return false
+}
+
+// A rough "these cards do the same job" key: mana value, colours and primary type (e.g. "3|B|Instant")
+func (ds *DeckSlot) getRedundancyKey() string {
+	return fmt.Sprintf("%d|%s|%s", int(ds.card.Cmc), strings.Join(ds.card.ColorIdentity, ""), ds.getPrimaryType())
+}
+
+// The most important type on the card's type line
+func (ds *DeckSlot) getPrimaryType() string {
+	for _, t := range []string{"Creature", "Planeswalker", "Instant", "Sorcery", "Artifact", "Enchantment", "Battle", "Land"} {
+		if ds.isCardType(t) {
+			return t
+		}
+	}
+	return "Other"
+}
+
 // Checks if the card has a specific (case sensitive) type
 func (ds *DeckSlot) isCardType(typePhrase string) bool {
 	return strings.Contains(ds.card.getTypeLineClean(), typePhrase)
 }
 
+// Does the card count as the given type (e.g. Creature) for the card type facts?  Double-faced cards go by their front face, while
+// split, aftermath and adventure cards count every half's types.  Only the types count, not the subtypes after the dash.
+func (ds *DeckSlot) countsAsCardType(cardType string) bool {
+	faces := strings.Split(ds.card.getTypeLineClean(), " // ")
+	if containsString(doubleFacedLayouts, ds.card.Layout) {
+		faces = faces[:1]
+	}
+	for _, face := range faces {
+		types := strings.SplitN(face, " - ", 2)[0]
+		for _, t := range strings.Fields(types) {
+			if strings.EqualFold(t, cardType) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // Handle grabbing the mana cost for a scryfall card.
 //
 // The complexity is that double-faced cards bury the value in the card faces.
@@ -731,13 +4124,10 @@ func (card *ScryfallCard) getManaCost() string {
 		return card.ManaCost
 	}
 
-	// Maybe dual-faced?
-	if len(card.CardFaces) > 0 {
-		if len(card.CardFaces[0].ManaCost) > 0 {
-			return card.CardFaces[0].ManaCost
-		}
-		if len(card.CardFaces[1].ManaCost) > 0 {
-			return card.CardFaces[1].ManaCost
+	// Maybe dual-faced?  Take the first face with a cost (some layouts only have the one face).
+	for _, face := range card.CardFaces {
+		if len(face.ManaCost) > 0 {
+			return face.ManaCost
 		}
 	}
 
@@ -745,6 +4135,31 @@ func (card *ScryfallCard) getManaCost() string {
 	return ""
 }
 
+// The card's price in dollars.  Scryfall leaves usd empty (null) for a lot of promos & foil-only printings, so fall back to the
+// foil price, and then to the euro price taken at face value.  ok is false if there's no usable price at all.
+func (card *ScryfallCard) getPriceUSD() (price float64, ok bool) {
+	for _, candidate := range []string{card.Prices.Usd, card.Prices.UsdFoil, card.Prices.Eur} {
+		if price, err := strconv.ParseFloat(strings.TrimSpace(candidate), 64); err == nil {
+			return price, true
+		}
+	}
+	return 0, false
+}
+
+// Was this printing of the card from the given set?  Scryfall files a set's promos under "p" + the set code, so count those as well.
+func (card *ScryfallCard) isFromSet(setCode string) bool {
+	return strings.EqualFold(card.Set, setCode) || strings.EqualFold(card.Set, "p"+setCode)
+}
+
+// Gather up the lower-cased oracle text for a card, including all faces of double-faced cards.
+func (card *ScryfallCard) getOracleText() string {
+	text := card.OracleText
+	for _, face := range card.CardFaces {
+		text += "\n" + face.OracleText
+	}
+	return strings.ToLower(text)
+}
+
 func getCardPrevalenceThreshold(rarity string) int {
 	if rarity == "uncommon" {
 		return seventeenLandsDrawnThreshold / 2
@@ -759,15 +4174,70 @@ func getCardPrevalenceThreshold(rarity string) int {
 	return seventeenLandsDrawnThreshold
 }
 
-// Eliminate the funky dash from the type line
+// The card's legality (legal, not_legal, banned, restricted) in a format, or an empty string for a format we don't know
+func (card *ScryfallCard) getLegality(format string) string {
+	switch strings.ToLower(format) {
+	case "standard":
+		return card.Legalities.Standard
+	case "future":
+		return card.Legalities.Future
+	case "historic":
+		return card.Legalities.Historic
+	case "gladiator":
+		return card.Legalities.Gladiator
+	case "pioneer":
+		return card.Legalities.Pioneer
+	case "modern":
+		return card.Legalities.Modern
+	case "legacy":
+		return card.Legalities.Legacy
+	case "pauper":
+		return card.Legalities.Pauper
+	case "vintage":
+		return card.Legalities.Vintage
+	case "penny":
+		return card.Legalities.Penny
+	case "commander":
+		return card.Legalities.Commander
+	case "brawl":
+		return card.Legalities.Brawl
+	case "historicbrawl":
+		return card.Legalities.Historicbrawl
+	case "alchemy":
+		return card.Legalities.Alchemy
+	case "paupercommander":
+		return card.Legalities.Paupercommander
+	case "duel":
+		return card.Legalities.Duel
+	case "oldschool":
+		return card.Legalities.Oldschool
+	case "premodern":
+		return card.Legalities.Premodern
+	}
+	return ""
+}
+
+// Eliminate the funky dash from the type line.  Any face type lines that the top-level one is missing (split & aftermath halves,
+// or cards with no top-level type line at all) are tacked on, so that both halves' types count.
 func (card *ScryfallCard) getTypeLineClean() string {
-	return strings.Replace(card.TypeLine, "—", "-", -1)
+	typeLine := card.TypeLine
+	for _, face := range card.CardFaces {
+		if face.TypeLine == "" || strings.Contains(typeLine, face.TypeLine) {
+			continue
+		}
+		if typeLine == "" {
+			typeLine = face.TypeLine
+		} else {
+			typeLine += " // " + face.TypeLine
+		}
+	}
+	return strings.Replace(typeLine, "—", "-", -1)
 }
 
-func dumpPerfromanceData(db *badger.DB, currentSet string) {
+func (run *Run) dumpPerfromanceData(db CardStore, currentSet string) {
 
 	// Open the output file
-	outputFileName := fmt.Sprintf("%s\\%s_%d_%d_%d_%d_%d.csv", perfOutputPath, currentSet, time.Now().Year(), time.Now().Month(), time.Now().Day(), time.Now().Hour(), time.Now().Minute())
+	outputFileName := filepath.Join(run.config.PerfOutputPath, fmt.Sprintf("%s_%s.csv", currentSet, getFileTimestamp()))
 	outputFile, err := os.Create(outputFileName)
 	checkError(err)
 	writer := bufio.NewWriter(outputFile)
@@ -775,8 +4245,8 @@ func dumpPerfromanceData(db *badger.DB, currentSet string) {
 	writer.WriteString("Card,URL,Rarity,Colour,Deck,GIH WR\n")
 
 	// Grab 17lands perf data for the set
-	for _, deckId := range getDecks(currentSet) {
-		cp, err := getCardPerformanceData(db, currentSet, deckId, debugging17Lands)
+	for _, deckId := range run.getDecks(currentSet) {
+		cp, err := run.getCardPerformanceData(db, currentSet, setPerformanceFormat, deckId, debugging17Lands)
 		checkError(err)
 
 		// Extract the GIH_WR for each card and dump to file
@@ -810,91 +4280,337 @@ func makePool(player string, team string, uri string, wins int, losses int) Play
 	// Pool is alive if losses is still within the threshold
 	isAlive := losses < leagueEliminationLosses
 
-	// Rip the suffix from a pool link, and add it to the API call
-	poolLink := uri
+	// Add-pack leagues get a new pool id every week, so the link can be a history of pools (oldest first).  The latest is the one we analyze.
+	poolLinks := strings.FieldsFunc(uri, func(r rune) bool {
+		return r == ',' || r == ';' || unicode.IsSpace(r)
+	})
+	var poolUri, previousPoolUri = "", ""
+	if len(poolLinks) > 0 {
+		poolUri = getPoolApiUri(poolLinks[len(poolLinks)-1])
+	}
+	if len(poolLinks) > 1 {
+		previousPoolUri = getPoolApiUri(poolLinks[len(poolLinks)-2])
+	}
+	var record string = fmt.Sprintf("%d | %d", wins, losses)
+
+	return PlayerPool{player: player, team: team, uri: poolUri, previousUri: previousPoolUri, isAlive: isAlive, record: record, wins: wins, losses: losses}
+}
+
+// Rip the suffix from a pool link, and add it to the API call
+func getPoolApiUri(poolLink string) string {
 	var lastSlash = strings.LastIndex(poolLink, "/")
 	var poolId = poolLink[lastSlash+1:]
-	var poolUri string = fmt.Sprintf(sealedDeckApiUriTemplate, poolId)
-	var record string = fmt.Sprintf("%d | %d", wins, losses)
+	return fmt.Sprintf(sealedDeckApiUriTemplate, poolId)
+}
 
-	return PlayerPool{player: player, team: team, uri: poolUri, isAlive: isAlive, record: record, facts: make(map[string]int)}
+// Let an explicit status (e.g. a player that dropped) override the alive/dead state inferred from losses.  Anything unrecognized is ignored.
+func (pool *PlayerPool) applyStatus(status string) {
+	switch strings.ToLower(strings.TrimSpace(status)) {
+	case "alive", "active", "in":
+		pool.isAlive = true
+	case "dead", "eliminated", "dropped", "out":
+		pool.isAlive = false
+	}
 }
 
-// Grab a json blob from the specific database for the given key, or nil if there is no value at that key
-func dbGet(db *badger.DB, key string) (resultJson string, err error) {
-	// Get the single card from the database
-	err = db.View(func(txn *badger.Txn) error {
-		item, err := txn.Get([]byte(key))
-		if err != nil {
-			return err
-		}
+// Grab a json blob from the specific database for the given key, or errKeyNotFound if there is no value at that key
+func dbGet(db CardStore, key string) (resultJson string, err error) {
+	return db.Get(key)
+}
 
-		var valCopy []byte
-		err = item.Value(func(val []byte) error {
-			// This func with val would only be called if item.Value encounters no error.
-			valCopy = append([]byte{}, val...)
-			return nil
-		})
-		if err != nil {
-			return err
-		}
+// Set a string value into a key in the database.
+func dbSet(db CardStore, key, value string) error {
+	err := db.Set(key, value)
+	if err != nil {
+		fmt.Fprintf(progress, "Failed to set key %s: %v\n", key, err)
+		return err
+	}
 
-		// Must copy it to use it outside item.Value(...).
-		resultJson = fmt.Sprintf("%s", valCopy)
-		return nil
-	})
+	return nil
+}
 
-	return resultJson, err
+// Delete a key from the database.
+func dbDelete(db CardStore, key string) error {
+	return db.Delete(key)
 }
 
-// Set a string value into a key in the database.
-func dbSet(db *badger.DB, key, value string) error {
-	err := db.Update(func(txn *badger.Txn) error {
-		return txn.Set([]byte(key), []byte(value))
-	})
+// Grab every key & value in the database.
+func dbGetAll(db CardStore) (entries map[string]string, err error) {
+	return db.GetAll()
+}
+
+// Constructor for the shared web client.  A timeout of 0 means no limit on the whole request.
+func makeHttpClient(transport http.RoundTripper, timeout time.Duration) *http.Client {
+	return &http.Client{Transport: transport, Timeout: timeout}
+}
 
+// Build a request with the headers that every site gets: who we are (-user-agent), and that we want json back
+func newWebRequest(method string, uri string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequest(method, uri, body)
 	if err != nil {
-		fmt.Printf("Failed to set key %s: %v\n", key, err)
-		return err
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", "application/json")
+	return req, nil
+}
+
+// The default transport, which routes through HTTP_PROXY/HTTPS_PROXY (and skips NO_PROXY hosts) when they're set
+func makeHttpTransport() http.RoundTripper {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	transport.ResponseHeaderTimeout = webTimeout // even without a client timeout, a site that never answers shouldn't hang the run
+	return transport
+}
+
+// Note the outcome of a web request.  Returns errServicesDown once the failures look like an outage rather than bad luck.
+func (tally *WebFailureTally) record(err error, isRetry bool) error {
+	tally.mu.Lock()
+	defer tally.mu.Unlock()
+
+	if tally.down {
+		return errServicesDown
+	}
+	if !isOutageError(err) {
+		tally.consecutive = 0
+		return nil
 	}
 
+	tally.consecutive += 1
+	if isRetry {
+		tally.retries += 1
+	}
+	if tally.consecutive >= webMaxConsecutiveFailures || tally.retries >= webRetryBudget {
+		tally.down = true
+		tally.lastErr = err
+		return errServicesDown
+	}
 	return nil
 }
 
+// Have the failures added up to an outage?
+func (tally *WebFailureTally) isDown() bool {
+	tally.mu.Lock()
+	defer tally.mu.Unlock()
+	return tally.down
+}
+
+// End the run with an error if the web failures looked like an outage.  main defers this first, so that it runs after the rest of the clean up.
+func exitIfServicesDown() {
+	webFailures.mu.Lock()
+	down, consecutive, retries, lastErr := webFailures.down, webFailures.consecutive, webFailures.retries, webFailures.lastErr
+	webFailures.mu.Unlock()
+
+	if down {
+		fmt.Fprintf(os.Stderr, "%v (%d failures in a row, %d retries so far), giving up.  Last error: %v\n", errServicesDown, consecutive, retries, lastErr)
+		os.Exit(1)
+	}
+}
+
+// Does the error look like the site is having trouble (rather than, say, a card that doesn't exist)?
+// Only a 5xx, a 429 or a failure to get a response at all (a timeout, a refused or dropped connection) counts.
+func isOutageError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var webErr *WebError
+	if errors.As(err, &webErr) {
+		return webErr.statusCode >= 500 || webErr.statusCode == 429
+	}
+	var netErr net.Error
+	var urlErr *url.Error
+	return errors.As(err, &netErr) || errors.As(err, &urlErr)
+}
+
 // Helper method that takes a Uri and spits out the response as a string
 // Retries a few times if an error is hit
 func getWebResponseString(uri string, retryMs int) (rawResult string, err error) {
+	if webFailures.isDown() {
+		return "", errServicesDown
+	}
 
 	// Try to hit the uri, and retry if an error code comes back.
 	for i := 0; i < webRetires; i++ {
 		var r string = ""
 		r, err = innerGetWebResponseString(uri)
+		if downErr := webFailures.record(err, i > 0); downErr != nil {
+			return "", downErr
+		}
 		if err == nil {
 			return r, err
 		}
 
-		// Something happened - take a nap, and then iterate
-		time.Sleep(time.Duration(retryMs) * time.Millisecond)
+		// Something happened - take a nap, and then iterate.  A site that timed out is probably swamped, so give it longer each time.
+		time.Sleep(getRetryPause(err, retryMs, i))
 	}
 
 	// If we got this far we were unsuccessful.  Return the final error
 	return "", err
 }
 
+// Post a json body to the uri and return the response, retrying like getWebResponseString
+func postWebResponseString(uri string, body string, retryMs int) (rawResult string, err error) {
+	if webFailures.isDown() {
+		return "", errServicesDown
+	}
+
+	for i := 0; i < webRetires; i++ {
+		var r string = ""
+		r, err = innerPostWebResponseString(uri, body)
+		if downErr := webFailures.record(err, i > 0); downErr != nil {
+			return "", downErr
+		}
+		if err == nil {
+			return r, err
+		}
+
+		time.Sleep(getRetryPause(err, retryMs, i))
+	}
+
+	return "", err
+}
+
+// How long to wait before retrying a request: the site's usual pause, doubled for each attempt that timed out
+func getRetryPause(err error, retryMs int, attempt int) time.Duration {
+	pause := time.Duration(retryMs) * time.Millisecond
+	if os.IsTimeout(err) {
+		pause <<= attempt + 1
+	}
+	return pause
+}
+
+// Helper method that posts a json body to a Uri and spits out the response as a string
+func innerPostWebResponseString(uri string, body string) (rawResult string, err error) {
+	var statusCode = 0
+	release := webScheduler.acquire(uri)
+	defer func() { release(statusCode) }()
+
+	req, err := newWebRequest("POST", uri, strings.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	statusCode = resp.StatusCode
+
+	if resp.StatusCode != 200 {
+		return "", &WebError{statusCode: resp.StatusCode, uri: uri}
+	}
+
+	responseBody, err := ioutil.ReadAll(resp.Body)
+	return string(responseBody), err
+}
+
 // Helper method that takes a Uri and spits out the response as a string
 func innerGetWebResponseString(uri string) (rawResult string, err error) {
-	resp, err := http.Get(uri)
-	checkError(err)
+	var statusCode = 0
+	release := webScheduler.acquire(uri)
+	defer func() { release(statusCode) }()
 
-	if resp.StatusCode != 200 {
-		err = errors.New(fmt.Sprintf("An error with code %d was throw trying to get a response from: %s", resp.StatusCode, uri))
+	req, err := newWebRequest("GET", uri, nil)
+	if err != nil {
 		return "", err
 	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", err // timeouts & dropped connections get retried
+	}
+	defer resp.Body.Close()
+	statusCode = resp.StatusCode
+
+	if resp.StatusCode != 200 {
+		return "", &WebError{statusCode: resp.StatusCode, uri: uri}
+	}
 
 	body, err := ioutil.ReadAll(resp.Body)
-	checkError(err)
+	if err != nil {
+		return "", err
+	}
+
+	return string(body), nil
+}
+
+// Turn a list of values into percentile ranks (0-100): the percentage of the other values that each value beats.
+func percentileRanks(values []float64) []int {
+	ranks := make([]int, len(values))
+	if len(values) < 2 {
+		return ranks
+	}
+
+	for i, v := range values {
+		var beaten = 0
+		for _, other := range values {
+			if other < v {
+				beaten += 1
+			}
+		}
+		ranks[i] = int(math.Round(100.0 * float64(beaten) / float64(len(values)-1)))
+	}
+	return ranks
+}
+
+// Squash a list of values into the 0-1 range (min-max).  If every value is the same, they all become 0.
+func normalize(values []float64) []float64 {
+	normalized := make([]float64, len(values))
+	if len(values) == 0 {
+		return normalized
+	}
+
+	var min, max = values[0], values[0]
+	for _, v := range values {
+		min = math.Min(min, v)
+		max = math.Max(max, v)
+	}
+	if max == min {
+		return normalized
+	}
+
+	for i, v := range values {
+		normalized[i] = (v - min) / (max - min)
+	}
+	return normalized
+}
+
+// Make a string safe to use as a file name on any OS
+func sanitizeFileName(name string) string {
+	safe := unsafeFileNameChars.ReplaceAllString(strings.TrimSpace(name), "_")
+	safe = strings.Trim(safe, "._")
+	if safe == "" {
+		return "pool"
+	}
+	return safe
+}
+
+// Wrap a csv field in quotes if it needs them (card names love commas)
+func csvQuote(s string) string {
+	if !strings.ContainsAny(s, ",\"\n") {
+		return s
+	}
+	return "\"" + strings.ReplaceAll(s, "\"", "\"\"") + "\""
+}
+
+// Is the string in the list?
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
 
-	return string(body), err
+// Does the text match any of the supplied patterns?
+func matchesAny(text string, patterns []*regexp.Regexp) bool {
+	for _, p := range patterns {
+		if p.MatchString(text) {
+			return true
+		}
+	}
+	return false
 }
 
 // Dumb little function to make error handling easier.
@@ -914,15 +4630,14 @@ func checkError(err error) {
 
 // Autogenerated sealeddeck.tech struct.
 type SealedDeck struct {
-	PoolID    string `json:"poolId"`
-	Sideboard []struct {
-		Name  string `json:"name"`
-		Count int    `json:"count"`
-	} `json:"sideboard"`
-	Deck []struct {
-		Name  string `json:"name"`
-		Count int    `json:"count"`
-	} `json:"deck"`
+	PoolID    string           `json:"poolId"`
+	Sideboard []SealedDeckCard `json:"sideboard"`
+	Deck      []SealedDeckCard `json:"deck"`
+}
+
+type SealedDeckCard struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
 }
 
 // Autogenerated scryfall struct.
@@ -1061,6 +4776,138 @@ type ScryfallCard struct {
 	} `json:"purchase_uris"`
 }
 
+// Scryfall list object (e.g. search results).  The cards are kept raw so that they can be cached as-is.
+type ScryfallList struct {
+	Object     string            `json:"object"`
+	TotalCards int               `json:"total_cards"`
+	HasMore    bool              `json:"has_more"`
+	NextPage   string            `json:"next_page"`
+	Data       []json.RawMessage `json:"data"`
+}
+
+// What the scryfall collection endpoint hands back: the cards it found, and the identifiers it didn't
+type ScryfallCollection struct {
+	Object   string            `json:"object"`
+	NotFound []json.RawMessage `json:"not_found"`
+	Data     []json.RawMessage `json:"data"`
+}
+
+// Where to download one of scryfall's bulk data files
+type ScryfallBulkData struct {
+	Object      string `json:"object"`
+	Type        string `json:"type"`
+	UpdatedAt   string `json:"updated_at"`
+	DownloadURI string `json:"download_uri"`
+}
+
+// Just the bits of a bulk data card that the price refresh needs
+type ScryfallBulkCard struct {
+	ID     string          `json:"id"`
+	Prices json.RawMessage `json:"prices"`
+}
+
+// The draft archetypes (17lands colour filters) that make sense for a set.
+type SetArchetypes struct {
+	TwoColour   []string `json:"twoColour"`
+	ThreeColour []string `json:"threeColour"`
+}
+
+// A pool (and everything we figured out about it) as it's written out to json.
+type PoolReport struct {
+	Player  string    `json:"player"`
+	Team    string    `json:"team"`
+	IsAlive bool      `json:"isAlive"`
+	Record  string    `json:"record"`
+	Stats   PoolStats `json:"stats"`
+
+	DeckStrengths map[string]float64 `json:"deckStrengths"` // each archetype's summed win rate, before the top 3 get blended into the strength
+	Cards         []PoolReportCard   `json:"cards,omitempty"`
+}
+
+type PoolReportCard struct {
+	Name   string `json:"name"`
+	Amount int    `json:"amount"`
+	Set    string `json:"set"`
+	Rarity string `json:"rarity"`
+}
+
+// The machine- and league-specific settings, from config.json
+type Config struct {
+	GoogleApiSecretFile string `json:"googleApiSecretFile"` // service account key for the google sheet
+	DbPath              string `json:"dbPath"`
+	OutputPath          string `json:"outputPath"`
+	PerfOutputPath      string `json:"perfOutputPath"` // where dumps of the 17lands data go
+	LeagueSheetID       string `json:"leagueSheetID"`
+	PoolLinkRange       string `json:"poolLinkRange"` // e.g. Pools!A7:H67
+	CurrentSet          string `json:"currentSet"`
+}
+
+// Everything about the league being looked at in this run: its config, and what gets worked out about it along the way.
+// Kept together (rather than as package globals) so that two leagues could be run side by side.
+type Run struct {
+	config           Config
+	currentSet       string         // comes from the config (or the pools, with -detect-set)
+	setsInPools      map[string]int // how many cards from each set are in the pools
+	setsInPoolsMu    sync.Mutex     // pools are fetched concurrently, and they all note their sets here
+	bombList         map[string]DeckSlot
+	dudList          map[string]DeckSlot
+	topCommonList    map[string]DeckSlot
+	topCommanderList map[string]DeckSlot // HBG-specific
+
+	archetypesBySet      map[string]SetArchetypes
+	cardMemo             map[string]*ScryfallCard // cards already looked up this run, by db key, so repeats skip the db (the printing depends on currentSet)
+	cardMemoMu           sync.Mutex               // pools are fetched concurrently
+	perfFreshness        PerfDataFreshness        // how deep/fresh the strength data is, for the report
+	cardPicks            map[string]CardPick      // average pick position by card name, from whichever deck saw the most picks
+	cardRarities         map[string]string        // 17lands rarity by card name
+	fieldColourShares    map[string]float64       // each colour's share of the coloured cards across the living pools, for -contested-colours
+	phaseTimings         []PhaseTiming            // how long each phase of the run took, for -timings (uses the real clock, not nowFunc)
+	curatedListMatches   map[string]bool          // answers for curated list lookups that weren't exact matches, by list & card name
+	curatedListMatchesMu sync.Mutex
+}
+
+// Constructor for a run of the given league
+func makeRun(config Config) *Run {
+	return &Run{
+		config:             config,
+		currentSet:         config.CurrentSet,
+		setsInPools:        make(map[string]int),
+		archetypesBySet:    make(map[string]SetArchetypes),
+		cardMemo:           make(map[string]*ScryfallCard),
+		perfFreshness:      PerfDataFreshness{GamesByDeck: make(map[string]int)},
+		cardPicks:          make(map[string]CardPick),
+		cardRarities:       make(map[string]string),
+		fieldColourShares:  make(map[string]float64),
+		phaseTimings:       make([]PhaseTiming, 0),
+		curatedListMatches: make(map[string]bool),
+	}
+}
+
+// How a run's reports were made: the version, the options, and the data that went in
+type RunManifest struct {
+	Version              string            `json:"version"`
+	GeneratedAt          string            `json:"generatedAt"`
+	CurrentSet           string            `json:"currentSet"`
+	SetsInPools          []string          `json:"setsInPools"`
+	Config               map[string]string `json:"config"` // every command-line option, defaults included
+	PerfData             PerfDataFreshness `json:"perfData"`
+	Pools                int               `json:"pools"`
+	AlivePools           int               `json:"alivePools"`
+	DeadPools            int               `json:"deadPools"`
+	PoolsWithoutCardData int               `json:"poolsWithoutCardData"`
+	UniqueCards          int               `json:"uniqueCards"`
+	TotalCards           int               `json:"totalCards"`
+}
+
+// How fresh and deep the 17lands data behind the strength numbers is.
+type PerfDataFreshness struct {
+	Set         string         `json:"set"`
+	Formats     []string       `json:"formats"`
+	FetchedAt   string         `json:"fetchedAt"`
+	GamesByDeck map[string]int `json:"gamesByDeck"`
+	TotalGames  int            `json:"totalGames"`
+}
+
 // Autogenerated 17lands.com struct.
 type CardPerformance []struct {
 	SeenCount               int     `json:"seen_count"`
@@ -0,0 +1,529 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsDraftablePrinting(t *testing.T) {
+	tests := []struct {
+		cardJson string
+		expected bool
+	}{
+		{`{"name": "Sheoldred, the Apocalypse", "set": "dmu", "set_type": "expansion"}`, true},
+		{`{"name": "Llanowar Elves", "set": "m19", "set_type": "core"}`, true},
+		{`{"name": "Sheoldred, the Apocalypse", "set": "pdmu", "set_type": "promo"}`, false},
+		{`{"name": "Sol Ring", "set": "c21", "set_type": "commander"}`, false},
+	}
+	for _, test := range tests {
+		if actual := isDraftablePrinting(test.cardJson); actual != test.expected {
+			t.Errorf("%s: expected %v, got %v", test.cardJson, test.expected, actual)
+		}
+	}
+}
+
+func TestIsCurrentSetPrinting(t *testing.T) {
+	run := &Run{currentSet: "HBG"}
+	if !run.isCurrentSetPrinting(`{"set": "hbg", "set_type": "alchemy"}`) {
+		t.Error("the current set's alchemy printing should count as the current set")
+	}
+	if run.isCurrentSetPrinting(`{"set": "dmu", "set_type": "expansion"}`) {
+		t.Error("another set's printing shouldn't count as the current set")
+	}
+}
+
+func TestSelectBestPrintingSkipsPromos(t *testing.T) {
+	run := &Run{currentSet: "DMU"}
+	printings := []json.RawMessage{
+		json.RawMessage(`{"name": "Sheoldred, the Apocalypse", "set": "pdmu", "set_type": "promo", "promo": true, "highres_image": true}`),
+		json.RawMessage(`{"name": "Sheoldred, the Apocalypse", "set": "dmu", "set_type": "expansion", "highres_image": true}`),
+	}
+
+	resultJson, err := run.selectBestPrinting(printings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	card := new(ScryfallCard)
+	json.Unmarshal([]byte(resultJson), &card)
+	if card.Promo || card.Set != "dmu" {
+		t.Errorf("expected the dmu expansion printing, got set %s (promo: %v)", card.Set, card.Promo)
+	}
+}
+
+func TestSelectBestPrintingPrefersCurrentSet(t *testing.T) {
+	run := &Run{currentSet: "HBG"}
+	printings := []json.RawMessage{
+		json.RawMessage(`{"name": "Llanowar Elves", "set": "dmu", "set_type": "expansion", "highres_image": true}`),
+		json.RawMessage(`{"name": "Llanowar Elves", "set": "hbg", "set_type": "alchemy", "highres_image": true}`),
+	}
+
+	resultJson, err := run.selectBestPrinting(printings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	card := new(ScryfallCard)
+	json.Unmarshal([]byte(resultJson), &card)
+	if card.Set != "hbg" {
+		t.Errorf("expected the current set's printing, got set %s", card.Set)
+	}
+}
+
+func TestCuratedListIgnoresBasics(t *testing.T) {
+	bombList := map[string]DeckSlot{
+		"Plains":                    {amount: 1, cardName: "Plains"},
+		"Sheoldred, the Apocalypse": {amount: 1, cardName: "Sheoldred, the Apocalypse"},
+	}
+	removeBasicLands(bombList)
+
+	run := makeRun(Config{})
+	if run.isInCuratedSet("Plains", bombList) {
+		t.Error("Plains shouldn't count as a bomb")
+	}
+	if !run.isInCuratedSet("Sheoldred, the Apocalypse", bombList) {
+		t.Error("Sheoldred should still be a bomb")
+	}
+}
+
+// A sealed pool-sized deck: 90 cards, a few duplicated, with a 23 card main deck
+func makeBenchmarkDeck(b *testing.B, seed int) *SealedDeck {
+	var deck, sideboard []string
+	for i := 0; i < 90; i++ {
+		card := fmt.Sprintf(`{"name": "Card %d", "count": %d}`, (seed*7+i)%250, 1+i%2)
+		if i < 23 {
+			deck = append(deck, card)
+		} else {
+			sideboard = append(sideboard, card)
+		}
+	}
+
+	sealedDeck := new(SealedDeck)
+	poolJson := fmt.Sprintf(`{"poolId": "pool%d", "deck": [%s], "sideboard": [%s]}`, seed, strings.Join(deck, ", "), strings.Join(sideboard, ", "))
+	if err := json.Unmarshal([]byte(poolJson), &sealedDeck); err != nil {
+		b.Fatal(err)
+	}
+	return sealedDeck
+}
+
+func BenchmarkFlatten(b *testing.B) {
+	deck := makeBenchmarkDeck(b, 1)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		deck.flatten()
+	}
+}
+
+func BenchmarkFlattenPools(b *testing.B) {
+	pools := make([]PlayerPool, 0, 300)
+	for p := 0; p < 300; p++ {
+		var pool PlayerPool
+		for _, slot := range makeBenchmarkDeck(b, p).flatten() {
+			pool.cards = append(pool.cards, slot)
+		}
+		pools = append(pools, pool)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		flattenPools(pools)
+	}
+}
+
+func TestGetFrontFaceName(t *testing.T) {
+	tests := []struct {
+		cardName string
+		expected string
+	}{
+		{"Delver of Secrets // Insectile Aberration", "Delver of Secrets"},
+		{"delver of secrets // insectile aberration", "delver of secrets"},
+		{"Llanowar Elves", "Llanowar Elves"},
+		{" // x", " // x"}, // no front face to speak of, so the whole name gets looked up
+	}
+	for _, test := range tests {
+		if actual := getFrontFaceName(test.cardName); actual != test.expected {
+			t.Errorf("%q: expected %q, got %q", test.cardName, test.expected, actual)
+		}
+	}
+}
+
+// Decode a card from scryfall-shaped json, for the tests
+func makeTestCard(t *testing.T, cardJson string) *ScryfallCard {
+	card := new(ScryfallCard)
+	if err := json.Unmarshal([]byte(cardJson), &card); err != nil {
+		t.Fatal(err)
+	}
+	return card
+}
+
+func TestGetTypeLineCleanSplitCards(t *testing.T) {
+	tests := []struct {
+		name     string
+		cardJson string
+		expected string
+	}{
+		{"split card", `{"name": "Fire // Ice", "layout": "split", "type_line": "Instant // Instant", "card_faces": [{"name": "Fire", "type_line": "Instant"}, {"name": "Ice", "type_line": "Instant"}]}`, "Instant // Instant"},
+		{"split card with only face type lines", `{"name": "Fire // Ice", "layout": "split", "card_faces": [{"name": "Fire", "type_line": "Instant"}, {"name": "Ice", "type_line": "Instant"}]}`, "Instant"},
+		{"aftermath card with only face type lines", `{"name": "Commit // Memory", "layout": "aftermath", "card_faces": [{"name": "Commit", "type_line": "Instant"}, {"name": "Memory", "type_line": "Sorcery"}]}`, "Instant // Sorcery"},
+		{"creature", `{"name": "Llanowar Elves", "type_line": "Creature — Elf Druid"}`, "Creature - Elf Druid"},
+	}
+	for _, test := range tests {
+		if actual := makeTestCard(t, test.cardJson).getTypeLineClean(); actual != test.expected {
+			t.Errorf("%s: expected %q, got %q", test.name, test.expected, actual)
+		}
+	}
+}
+
+func TestFlattenAllSideboardPool(t *testing.T) {
+	deck := &SealedDeck{PoolID: "sideboard-only", Sideboard: []SealedDeckCard{{Name: "Llanowar Elves", Count: 2}, {Name: "Shock", Count: 1}}}
+	if deck.hasMainDeck() {
+		t.Error("a pool with an empty deck shouldn't have a main deck")
+	}
+
+	flattened := deck.flatten()
+	if len(flattened) != 2 {
+		t.Fatalf("expected 2 cards, got %d", len(flattened))
+	}
+	for name, slot := range flattened {
+		if slot.deckAmount != slot.amount {
+			t.Errorf("%s: expected all %d copies in the deck, got %d", name, slot.amount, slot.deckAmount)
+		}
+	}
+}
+
+func TestGetBlendedStrength(t *testing.T) {
+	tests := []struct {
+		name          string
+		deckStrengths map[string]float64
+		expected      float64
+	}{
+		{"three decks", map[string]float64{"WU": 0.5, "BR": 0.4, "GW": 0.3}, 94},
+		{"two non-zero decks", map[string]float64{"WU": 0.5, "BR": 0.4, "GW": 0, "UB": 0}, 82},
+		{"only two decks", map[string]float64{"WU": 0.5, "BR": 0.4}, 82},
+		{"one deck", map[string]float64{"WU": 0.5}, 50},
+		{"no decks", map[string]float64{}, 0},
+	}
+	for _, test := range tests {
+		if actual := getBlendedStrength(test.deckStrengths); math.Abs(actual-test.expected) > 0.0001 {
+			t.Errorf("%s: expected %.2f, got %.2f", test.name, test.expected, actual)
+		}
+	}
+}
+
+func TestGetCardDbKey(t *testing.T) {
+	tests := []struct {
+		cardName string
+		expected string
+	}{
+		{"A-Anax, Hardened in the Forge", "anax, hardened in the forge"},
+		{"A-Ajani, Nacatl Pariah", "ajani, nacatl pariah"},
+		{"Llanowar Elves", "llanowar elves"},
+		{"Mana Tithe", "mana tithe"},
+		{"a-okay", "a-okay"}, // not the Alchemy designation, just a name that starts like it
+	}
+	for _, test := range tests {
+		if actual := getCardDbKey(test.cardName); actual != test.expected {
+			t.Errorf("%s: expected %q, got %q", test.cardName, test.expected, actual)
+		}
+	}
+}
+
+func TestGetManaCost(t *testing.T) {
+	tests := []struct {
+		name     string
+		cardJson string
+		expected string
+	}{
+		{"normal card", `{"name": "Llanowar Elves", "mana_cost": "{G}"}`, "{G}"},
+		{"one face", `{"name": "Odd Card", "card_faces": [{"name": "Odd Card", "mana_cost": "{1}{U}"}]}`, "{1}{U}"},
+		{"two-face DFC", `{"name": "Delver of Secrets // Insectile Aberration", "layout": "transform", "card_faces": [{"name": "Delver of Secrets", "mana_cost": "{U}"}, {"name": "Insectile Aberration", "mana_cost": ""}]}`, "{U}"},
+		{"cost on the back face only", `{"name": "Front // Back", "card_faces": [{"name": "Front", "mana_cost": ""}, {"name": "Back", "mana_cost": "{2}{R}"}]}`, "{2}{R}"},
+		{"no cost", `{"name": "Forest"}`, ""},
+	}
+	for _, test := range tests {
+		if actual := makeTestCard(t, test.cardJson).getManaCost(); actual != test.expected {
+			t.Errorf("%s: expected %q, got %q", test.name, test.expected, actual)
+		}
+	}
+}
+
+func TestCountsAsCardType(t *testing.T) {
+	tests := []struct {
+		name     string
+		cardJson string
+		cardType string
+		expected bool
+	}{
+		{"creature", `{"name": "Llanowar Elves", "type_line": "Creature — Elf Druid"}`, "Creature", true},
+		{"split instant half", `{"name": "Commit // Memory", "layout": "aftermath", "type_line": "Instant // Sorcery"}`, "Instant", true},
+		{"split sorcery half", `{"name": "Commit // Memory", "layout": "aftermath", "type_line": "Instant // Sorcery"}`, "Sorcery", true},
+		{"adventure", `{"name": "Bonecrusher Giant // Stomp", "layout": "adventure", "type_line": "Creature — Giant // Instant — Adventure"}`, "Instant", true},
+		{"transform front", `{"name": "Delver of Secrets // Insectile Aberration", "layout": "transform", "type_line": "Creature — Human Wizard // Creature — Human Insect"}`, "Creature", true},
+		{"modal dfc back", `{"name": "Emeria's Call // Emeria, Shattered Skyclave", "layout": "modal_dfc", "type_line": "Sorcery // Land"}`, "Land", false},
+		{"subtype isn't a type", `{"name": "Stomp", "type_line": "Instant — Adventure"}`, "Adventure", false},
+	}
+	for _, test := range tests {
+		ds := DeckSlot{cardName: test.name, card: makeTestCard(t, test.cardJson)}
+		if actual := ds.countsAsCardType(test.cardType); actual != test.expected {
+			t.Errorf("%s: expected %v for %s, got %v", test.name, test.expected, test.cardType, actual)
+		}
+	}
+}
+
+func TestGetRowTeam(t *testing.T) {
+	tests := []struct {
+		row             []interface{}
+		teamColumnIndex int
+		want            string
+	}{
+		{[]interface{}{"Alice", 5, 2, "link", " Red Team "}, 4, "Red Team"},
+		{[]interface{}{"Alice", 5, 2, "link", ""}, 4, unaffiliatedTeamName}, // blank team cell
+		{[]interface{}{"Alice", 5, 2, "link"}, 4, unaffiliatedTeamName},     // short row
+		{[]interface{}{"Alice", 5, 2, "link", "Red Team"}, -1, ""},          // no team column
+	}
+	for _, test := range tests {
+		if got := getRowTeam(test.row, test.teamColumnIndex); got != test.want {
+			t.Errorf("getRowTeam(%v, %d) = %q, want %q", test.row, test.teamColumnIndex, got, test.want)
+		}
+	}
+}
+
+func TestAddQualityFactsSkipsPoolsWithoutPicks(t *testing.T) {
+	pools := []PlayerPool{
+		{player: "early picks", stats: PoolStats{AvgWinRate: 0.58, AvgPick: 3.2}},
+		{player: "late picks", stats: PoolStats{AvgWinRate: 0.54, AvgPick: 6.8}},
+		{player: "no picks", stats: PoolStats{AvgWinRate: 0.56}},
+	}
+	addQualityFacts(pools)
+
+	if pools[0].stats.QualityScore != 100 || pools[1].stats.QualityScore != 0 {
+		t.Errorf("expected the scored pools to get 100 and 0, got %d and %d", pools[0].stats.QualityScore, pools[1].stats.QualityScore)
+	}
+	if pools[2].stats.QualityScore != 0 {
+		t.Errorf("a pool without pick data shouldn't be scored, got %d", pools[2].stats.QualityScore)
+	}
+}
+
+// An in-memory CardStore, for the tests
+type testStore map[string]string
+
+func (s testStore) Get(key string) (string, error) {
+	if value, ok := s[key]; ok {
+		return value, nil
+	}
+	return "", errKeyNotFound
+}
+func (s testStore) Set(key, value string) error        { s[key] = value; return nil }
+func (s testStore) Delete(key string) error            { delete(s, key); return nil }
+func (s testStore) GetAll() (map[string]string, error) { return s, nil }
+func (s testStore) Compact() error                     { return nil }
+func (s testStore) Close() error                       { return nil }
+
+// Answers every web request with the same json, and counts them
+type fakeTransport struct {
+	responseJson string
+	requests     int
+}
+
+func (f *fakeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	f.requests += 1
+	return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(f.responseJson)), Request: req}, nil
+}
+
+func TestCardPerformanceDataIsRefreshedDaily(t *testing.T) {
+	defer func(now func() time.Time, client *http.Client) { nowFunc, httpClient = now, client }(nowFunc, httpClient)
+	transport := &fakeTransport{responseJson: `[{"name": "Shock", "game_count": 1200, "ever_drawn_win_rate": 0.56}]`}
+	httpClient = makeHttpClient(transport, webTimeout)
+
+	run := &Run{currentSet: "DMU"}
+	db := testStore{}
+	day1 := time.Date(2022, 9, 20, 9, 0, 0, 0, time.Local)
+	nowFunc = func() time.Time { return day1 }
+	key1 := run.getCardPerformanceDbKey("DMU", defaultPerformanceFormat, "WU")
+	if key1 != "17lands_DMU_WU_2022_09_20" {
+		t.Errorf("expected the current set's key to carry the date, got %s", key1)
+	}
+	for _, hour := range []int{0, 8} {
+		nowFunc = func() time.Time { return day1.Add(time.Duration(hour) * time.Hour) }
+		if key := run.getCardPerformanceDbKey("DMU", defaultPerformanceFormat, "WU"); key != key1 {
+			t.Errorf("expected the same key later in the day, got %s", key)
+		}
+		if _, err := run.getCardPerformanceData(db, "DMU", defaultPerformanceFormat, "WU", false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if transport.requests != 1 {
+		t.Errorf("expected one fetch on the first day, got %d", transport.requests)
+	}
+
+	nowFunc = func() time.Time { return day1.AddDate(0, 0, 1) }
+	key2 := run.getCardPerformanceDbKey("DMU", defaultPerformanceFormat, "WU")
+	if key2 == key1 {
+		t.Errorf("expected a new key on the next day, got %s again", key2)
+	}
+	if _, err := run.getCardPerformanceData(db, "DMU", defaultPerformanceFormat, "WU", false); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := db[key2]; !ok || transport.requests != 2 {
+		t.Errorf("expected the next day's data to be fetched and stored under %s (%d fetches)", key2, transport.requests)
+	}
+
+	if key := run.getCardPerformanceDbKey("NEO", defaultPerformanceFormat, "WU"); key != "17lands_NEO_WU" {
+		t.Errorf("expected another set's key to have no date, got %s", key)
+	}
+}
+
+// A store that counts its reads
+type countingStore struct {
+	testStore
+	reads int
+}
+
+func (s *countingStore) Get(key string) (string, error) {
+	s.reads++
+	return s.testStore.Get(key)
+}
+
+func TestCardsCachedWithoutFetchTimeAreFresh(t *testing.T) {
+	defer func(now func() time.Time) { nowFunc = now }(nowFunc)
+	nowFunc = func() time.Time { return time.Date(2022, 9, 10, 12, 0, 0, 0, time.UTC) }
+
+	db := &countingStore{testStore: testStore{}}
+	db.Set("shock", `{"name": "Shock"}`)
+	if isCardFetchNeeded(db, "shock") {
+		t.Error("a card cached before fetch times were kept shouldn't be re-fetched")
+	}
+	if _, err := db.Get("shock_fetched"); err != nil {
+		t.Error("expected the fetch time to be written")
+	}
+
+	db.reads = 0
+	if isCardFetchNeeded(db, "shock") || db.reads != 1 {
+		t.Errorf("expected a fresh card and a single read, got %d reads", db.reads)
+	}
+	if !isCardFetchNeeded(db, "opt") {
+		t.Error("an uncached card needs fetching")
+	}
+
+	nowFunc = func() time.Time { return time.Date(2022, 9, 10, 12, 0, 0, 0, time.UTC).Add(cardTTL + time.Hour) }
+	if !isCardFetchNeeded(db, "shock") || !isCachedCardStale(db, "shock") {
+		t.Error("a card past its TTL needs fetching")
+	}
+}
+
+func TestSchedulerDoesNotHoldSlotsWhileSpacing(t *testing.T) {
+	scheduler := makeRequestScheduler(1, map[string]time.Duration{"slow.example": 500 * time.Millisecond})
+	scheduler.acquire("https://slow.example/1")(200)
+
+	// The second request to the slow host has to wait out its interval, but shouldn't take the only slot while it does
+	go func() { scheduler.acquire("https://slow.example/2")(200) }()
+	time.Sleep(50 * time.Millisecond)
+
+	start := time.Now()
+	scheduler.acquire("https://fast.example/1")(200)
+	if waited := time.Since(start); waited > 250*time.Millisecond {
+		t.Errorf("a request to another host waited %v behind the slow host", waited)
+	}
+}
+
+func TestIsOutageError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"no error", nil, false},
+		{"not found", &WebError{statusCode: 404, uri: "https://api.scryfall.com/cards/named"}, false},
+		{"server error", &WebError{statusCode: 503, uri: "https://api.scryfall.com/cards/named"}, true},
+		{"too many requests", &WebError{statusCode: 429, uri: "https://www.17lands.com/card_ratings/data"}, true},
+		{"connection refused", &url.Error{Op: "Get", URL: "https://sealeddeck.tech/api/pools/x", Err: errors.New("connection refused")}, true},
+		{"bad json", errors.New("Could not read the pool"), false},
+	}
+	for _, test := range tests {
+		if actual := isOutageError(test.err); actual != test.expected {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expected, actual)
+		}
+	}
+}
+
+func TestWebFailureTallyTrips(t *testing.T) {
+	tally := &WebFailureTally{}
+	serverError := &WebError{statusCode: 502, uri: "https://api.scryfall.com/cards/named"}
+	for i := 1; i < webMaxConsecutiveFailures; i++ {
+		if err := tally.record(serverError, false); err != nil {
+			t.Fatalf("gave up after only %d failures", i)
+		}
+	}
+	if err := tally.record(serverError, false); err != errServicesDown {
+		t.Errorf("expected errServicesDown after %d failures in a row, got %v", webMaxConsecutiveFailures, err)
+	}
+	if err := tally.record(nil, false); err != errServicesDown {
+		t.Errorf("expected the tally to stay down, got %v", err)
+	}
+}
+
+func TestGetCollectionPrices(t *testing.T) {
+	defer func(client *http.Client) { httpClient = client }(httpClient)
+	transport := &fakeTransport{responseJson: `{"object": "list", "not_found": [], "data": [{"id": "abc", "name": "Shock", "prices": {"usd": "0.10"}}, {"id": "def", "name": "Opt", "prices": {"usd": "0.05"}}]}`}
+	httpClient = makeHttpClient(transport, webTimeout)
+
+	pricesById, err := getCollectionPrices(map[string][]string{"abc": {"shock"}, "def": {"opt"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if transport.requests != 1 {
+		t.Errorf("expected one request for two printings, got %d", transport.requests)
+	}
+	if string(pricesById["abc"]) != `{"usd": "0.10"}` || string(pricesById["def"]) != `{"usd": "0.05"}` {
+		t.Errorf("expected each printing's prices, got %s", pricesById)
+	}
+}
+
+// A store that can be read but not written to, like a full disk
+type readOnlyStore struct{ testStore }
+
+func (s readOnlyStore) Set(key, value string) error { return errors.New("disk full") }
+
+func TestScryfallGetBatchReturnsCacheErrors(t *testing.T) {
+	defer func(client *http.Client) { httpClient = client }(httpClient)
+	defer func(w io.Writer) { progress = w }(progress)
+	progress = ioutil.Discard
+	httpClient = makeHttpClient(&fakeTransport{responseJson: `{"object": "list", "not_found": [], "data": [{"name": "Shock", "set": "dmu"}, {"name": "Opt", "set": "dmu"}]}`}, webTimeout)
+
+	run := &Run{currentSet: "DMU"}
+	notFound, err := run.scryfallGetBatch(readOnlyStore{testStore{}}, []string{"Shock", "Opt"})
+	if err == nil {
+		t.Fatal("expected the cache write to fail")
+	}
+	if len(notFound) != 2 {
+		t.Errorf("expected both cards to be left for the single lookups, got %v", notFound)
+	}
+}
+
+func TestCheckCuratedListSetsFindsAlchemyCards(t *testing.T) {
+	defer func(w io.Writer) { progress = w }(progress)
+	var output bytes.Buffer
+	progress = &output
+
+	run := &Run{currentSet: "DMU", setsInPools: map[string]int{"DMU": 1}}
+	db := testStore{}
+	db.Set(getCardDbKey("A-Sheoldred, the Apocalypse"), `{"name": "A-Sheoldred, the Apocalypse", "set": "dmu"}`)
+	db.Set(getCardDbKey("Llanowar Elves"), `{"name": "Llanowar Elves", "set": "m19"}`)
+	bombList := map[string]DeckSlot{
+		"A-Sheoldred, the Apocalypse": {amount: 1, cardName: "A-Sheoldred, the Apocalypse"},
+		"Llanowar Elves":              {amount: 1, cardName: "Llanowar Elves"},
+	}
+
+	run.checkCuratedListSets(db, map[string]map[string]DeckSlot{"bomb": bombList})
+	if strings.Contains(output.String(), "WARNING") {
+		t.Errorf("half of the list is from the set, so it shouldn't be flagged: %s", output.String())
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Every request to the outside world (scryfall, sealeddeck.tech, 17lands) goes through a scheduler, which:
+// 1. Caps how many requests are in flight at once, across all hosts
+// 2. Spaces out requests to the same host by that host's minimum interval
+// 3. Backs off *everything* when any host says we're going too fast (HTTP 429)
+type RequestScheduler struct {
+	mu            sync.Mutex
+	slots         chan struct{}
+	hostIntervals map[string]time.Duration
+	nextRequest   map[string]time.Time // earliest time the next request to a host may go out
+	backoff       time.Duration
+	backoffUntil  time.Time
+}
+
+const schedulerMinBackoff = 1 * time.Second
+const schedulerMaxBackoff = 60 * time.Second
+
+// Constructor for a scheduler.  Hosts without an interval are only subject to the global cap.
+func makeRequestScheduler(maxConcurrent int, hostIntervals map[string]time.Duration) *RequestScheduler {
+	if maxConcurrent < 1 {
+		maxConcurrent = 1
+	}
+	return &RequestScheduler{
+		slots:         make(chan struct{}, maxConcurrent),
+		hostIntervals: hostIntervals,
+		nextRequest:   make(map[string]time.Time),
+	}
+}
+
+// Wait until a request to the uri is allowed to go out.  Call the returned func with the response's status code (or 0 if there wasn't one) when done.
+func (s *RequestScheduler) acquire(uri string) (release func(statusCode int)) {
+	host := getHost(uri)
+
+	// Claim the next spot in the host's queue, then sleep until it (and any global backoff) comes around.
+	// Only then take a slot, so that requests waiting on a slow host don't hold up the other hosts.
+	s.mu.Lock()
+	start := nowFunc()
+	if s.nextRequest[host].After(start) {
+		start = s.nextRequest[host]
+	}
+	if s.backoffUntil.After(start) {
+		start = s.backoffUntil
+	}
+	s.nextRequest[host] = start.Add(s.hostIntervals[host])
+	s.mu.Unlock()
+
+	time.Sleep(time.Until(start))
+	s.slots <- struct{}{}
+
+	return func(statusCode int) {
+		s.mu.Lock()
+		if statusCode == 429 {
+			// Too many requests: slow everyone down, harder each time it happens
+			s.backoff *= 2
+			if s.backoff < schedulerMinBackoff {
+				s.backoff = schedulerMinBackoff
+			}
+			if s.backoff > schedulerMaxBackoff {
+				s.backoff = schedulerMaxBackoff
+			}
+			s.backoffUntil = nowFunc().Add(s.backoff)
+		} else if statusCode != 0 {
+			s.backoff = 0
+		}
+		s.mu.Unlock()
+
+		<-s.slots
+	}
+}
+
+// Pull the host out of a uri, or an empty string if it doesn't parse
+func getHost(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/dgraph-io/badger"
+	_ "modernc.org/sqlite"
+)
+
+// Where the cached cards, 17lands data, sheet copies, etc. live between runs.  Everything is a string value under a string key.
+// Badger is the default; SQLite (-store sqlite) keeps it all in one file that's easy to share and query.
+type CardStore interface {
+	Get(key string) (string, error) // errKeyNotFound if there's nothing there
+	Set(key, value string) error
+	Delete(key string) error
+	GetAll() (map[string]string, error)
+	Compact() error // hand back the space left behind by deleted & overwritten entries
+	Close() error
+}
+
+var errKeyNotFound = errors.New("Key not found")
+
+// Open the store of the given kind (badger or sqlite) under the configured db path
+func openCardStore(kind string, dbPath string) (CardStore, error) {
+	switch kind {
+	case "sqlite":
+		return makeSqliteStore(getCardStorePath(kind, dbPath))
+	case "badger":
+		return makeBadgerStore(getCardStorePath(kind, dbPath))
+	}
+	return nil, errors.New(fmt.Sprintf("Unknown store: %s", kind))
+}
+
+// Badger wants a directory, while SQLite is a single file next to where that directory would be
+func getCardStorePath(kind string, dbPath string) string {
+	if kind == "sqlite" {
+		return dbPath + ".sqlite"
+	}
+	return dbPath
+}
+
+type BadgerStore struct {
+	db *badger.DB
+}
+
+// Constructor for a badger store, in the directory at path
+func makeBadgerStore(path string) (*BadgerStore, error) {
+	db, err := badger.Open(badger.DefaultOptions(path))
+	if err != nil {
+		return nil, err
+	}
+	return &BadgerStore{db: db}, nil
+}
+
+// Grab a json blob from the specific database for the given key, or nil if there is no value at that key
+func (s *BadgerStore) Get(key string) (resultJson string, err error) {
+	// Get the single card from the database
+	err = s.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(key))
+		if err != nil {
+			return err
+		}
+
+		var valCopy []byte
+		err = item.Value(func(val []byte) error {
+			// This func with val would only be called if item.Value encounters no error.
+			valCopy = append([]byte{}, val...)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		// Must copy it to use it outside item.Value(...).
+		resultJson = fmt.Sprintf("%s", valCopy)
+		return nil
+	})
+
+	if err == badger.ErrKeyNotFound {
+		err = errKeyNotFound
+	}
+	return resultJson, err
+}
+
+func (s *BadgerStore) Set(key, value string) error {
+	return s.db.Update(func(txn *badger.Txn) error {
+		return txn.Set([]byte(key), []byte(value))
+	})
+}
+
+func (s *BadgerStore) Delete(key string) error {
+	return s.db.Update(func(txn *badger.Txn) error {
+		return txn.Delete([]byte(key))
+	})
+}
+
+func (s *BadgerStore) GetAll() (entries map[string]string, err error) {
+	entries = make(map[string]string)
+	err = s.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			item := it.Item()
+			value, err := item.ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			entries[string(item.KeyCopy(nil))] = string(value)
+		}
+		return nil
+	})
+
+	return entries, err
+}
+
+// Garbage collect the value log until there's nothing left worth rewriting
+func (s *BadgerStore) Compact() error {
+	for s.db.RunValueLogGC(0.5) == nil {
+	}
+	return nil
+}
+
+func (s *BadgerStore) Close() error {
+	return s.db.Close()
+}
+
+// Everything goes in one kv table, so the cache can be poked at with plain SQL (e.g. to look at a card's prices)
+type SqliteStore struct {
+	db *sql.DB
+}
+
+// Constructor for a SQLite store, in the file at path (created, along with the table, if needed)
+func makeSqliteStore(path string) (*SqliteStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1) // pools are fetched concurrently, and SQLite only takes one writer at a time anyway
+
+	_, err = db.Exec("CREATE TABLE IF NOT EXISTS kv (key TEXT PRIMARY KEY, value TEXT, updated_at INTEGER)")
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &SqliteStore{db: db}, nil
+}
+
+func (s *SqliteStore) Get(key string) (string, error) {
+	var value string
+	err := s.db.QueryRow("SELECT value FROM kv WHERE key = ?", key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", errKeyNotFound
+	}
+	return value, err
+}
+
+func (s *SqliteStore) Set(key, value string) error {
+	_, err := s.db.Exec("INSERT INTO kv (key, value, updated_at) VALUES (?, ?, ?) ON CONFLICT(key) DO UPDATE SET value = excluded.value, updated_at = excluded.updated_at",
+		key, value, nowFunc().Unix())
+	return err
+}
+
+func (s *SqliteStore) Delete(key string) error {
+	_, err := s.db.Exec("DELETE FROM kv WHERE key = ?", key)
+	return err
+}
+
+func (s *SqliteStore) GetAll() (map[string]string, error) {
+	rows, err := s.db.Query("SELECT key, value FROM kv")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, err
+		}
+		entries[key] = value
+	}
+	return entries, rows.Err()
+}
+
+func (s *SqliteStore) Compact() error {
+	_, err := s.db.Exec("VACUUM")
+	return err
+}
+
+func (s *SqliteStore) Close() error {
+	return s.db.Close()
+}